@@ -0,0 +1,49 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// checkModN is a plain digit-sum checksum: the sum of all the digits of the
+// string has to be a multiple of N. Spaces and hyphens are ignored, any
+// other character fails the check.
+func checkModN(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	mustBeString(TAG_FIELD_MOD_CHECK, structField, fValue)
+
+	mod, err := strconv.ParseUint(tagRawVal, 10, 64)
+	if err != nil || mod == 0 {
+		panic(fmt.Sprintf("Unable to parse '%s' tag as a positive integer", TAG_FIELD_MOD_CHECK))
+	}
+
+	if digitSum, ok := sumDigits(fValue.String()); ok && digitSum%mod == 0 {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' has an invalid checksum", vParams.Name),
+	}
+}
+
+func sumDigits(s string) (uint64, bool) {
+	sum, digits := uint64(0), 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			sum += uint64(r - '0')
+			digits++
+		case r == ' ' || r == '-':
+		default:
+			return 0, false
+		}
+	}
+
+	return sum, digits > 0
+}
@@ -0,0 +1,31 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestModCheck(t *testing.T) {
+	type voucher struct {
+		Code string `validate:"name=code,modcheck=7"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"code":"1234-5678-6"}`, nil},
+		{`{"code":"7 0 0"}`, nil},
+		{`{"code":"1234-5678-5"}`, &ValidateError{ParamName: "code", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"code":"12a4"}`, &ValidateError{ParamName: "code", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"code":" - "}`, &ValidateError{ParamName: "code", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out voucher
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var keyFuncs = struct {
+	sync.RWMutex
+	m map[string]func(elem interface{}) string
+}{
+	m: make(map[string]func(elem interface{}) string),
+}
+
+// RegisterKeyFunc makes fn available to the uniqueby tag, the elements of a
+// slice are considered equal when fn maps them to the same key. It panics
+// if the name is already registered.
+func RegisterKeyFunc(name string, fn func(elem interface{}) string) {
+	if name == "" || fn == nil {
+		panic("Key function must have a name and a function")
+	}
+
+	keyFuncs.Lock()
+	defer keyFuncs.Unlock()
+
+	if _, ok := keyFuncs.m[name]; ok {
+		panic(fmt.Sprintf("Key function '%s' already registered", name))
+	}
+
+	keyFuncs.m[name] = fn
+}
+
+func mustBeSlice(tagName string, structField reflect.StructField, fValue reflect.Value) {
+	if fValue.Kind() != reflect.Slice && fValue.Kind() != reflect.Array {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a slice or array", tagName, structField.Name))
+	}
+}
+
+func checkSum(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagName, tagRawVal string) *ValidateError {
+	mustBeSlice(tagName, structField, fValue)
+
+	bound, err := strconv.ParseFloat(tagRawVal, 64)
+	if err != nil {
+		return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as a float", tagName))
+	}
+
+	sum := 0.0
+	for i := 0; i < fValue.Len(); i++ {
+		v, ok := numericAsFloat(fValue.Index(i))
+		if !ok {
+			panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+				"The elements are not integers or floats", tagName, structField.Name))
+		}
+
+		sum += v
+	}
+
+	if tagName == TAG_FIELD_SUM_MIN && sum < bound {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_SMALL,
+			OriginalError: fmt.Errorf("Param '%s' sum is too small (< %v)", vParams.Name, bound),
+		}
+	}
+
+	if tagName == TAG_FIELD_SUM_MAX && sum > bound {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_BIG,
+			OriginalError: fmt.Errorf("Param '%s' sum is too big (> %v)", vParams.Name, bound),
+		}
+	}
+
+	return nil
+}
+
+func checkMapValues(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagName, tagRawVal string) *ValidateError {
+	mustBeMap(tagName, structField, fValue)
+
+	reqLen, err := strconv.ParseUint(tagRawVal, 10, 64)
+	if err != nil {
+		return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", tagName))
+	}
+
+	if tagName == TAG_FIELD_MAX_VALUES && fValue.Len() > int(reqLen) {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_LONG,
+			OriginalError: fmt.Errorf("Param '%s' has too many entries (> %d)", vParams.Name, reqLen),
+		}
+	}
+
+	if tagName == TAG_FIELD_MIN_VALUES && fValue.Len() < int(reqLen) {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_SHORT,
+			OriginalError: fmt.Errorf("Param '%s' has too few entries (< %d)", vParams.Name, reqLen),
+		}
+	}
+
+	return nil
+}
+
+// VALUE_SUM_TOLERANCE is the tolerance of valuesum when the tag gives none.
+const VALUE_SUM_TOLERANCE = 1e-9
+
+// checkValueSum requires the values of a numeric map to add up to the
+// target, tagged as valuesum=target or valuesum=target:tolerance.
+func checkValueSum(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	mustBeMap(TAG_FIELD_VALUE_SUM, structField, fValue)
+
+	parts := strings.SplitN(tagRawVal, ":", 2)
+	target, err := strconv.ParseFloat(parts[0], 64)
+	tolerance := VALUE_SUM_TOLERANCE
+	if err == nil && len(parts) == 2 {
+		tolerance, err = strconv.ParseFloat(parts[1], 64)
+	}
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse '%s' tag as target[:tolerance]", TAG_FIELD_VALUE_SUM))
+	}
+
+	sum := 0.0
+	for _, key := range sortedMapKeys(fValue) {
+		v, ok := numericAsFloat(fValue.MapIndex(key))
+		if !ok {
+			panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+				"The values are not integers or floats", TAG_FIELD_VALUE_SUM, structField.Name))
+		}
+
+		sum += v
+	}
+
+	if math.Abs(sum-target) > tolerance {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' values sum to %v instead of %v", vParams.Name, sum, target),
+		}
+	}
+
+	return nil
+}
+
+func checkContiguous(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeSlice(TAG_FIELD_CONTIGUOUS, structField, fValue)
+
+	for i := 1; i < fValue.Len(); i++ {
+		prev, cur := fValue.Index(i-1), fValue.Index(i)
+
+		var next bool
+		switch cur.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			next = prev.Int() != math.MaxInt64 && cur.Int() == prev.Int()+1
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			next = prev.Uint() != math.MaxUint64 && cur.Uint() == prev.Uint()+1
+		default:
+			panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+				"The elements are not integers", TAG_FIELD_CONTIGUOUS, structField.Name))
+		}
+
+		if !next {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' is not contiguous at index %d", vParams.Name, i),
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkUniqueBy(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, name string) *ValidateError {
+	mustBeSlice(TAG_FIELD_UNIQUE_BY, structField, fValue)
+
+	keyFuncs.RLock()
+	fn, ok := keyFuncs.m[name]
+	keyFuncs.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Unknown key function '%s' on field '%s'", name, structField.Name))
+	}
+
+	seen := make(map[string]int, fValue.Len())
+	for i := 0; i < fValue.Len(); i++ {
+		key := fn(fValue.Index(i).Interface())
+		if first, ok := seen[key]; ok {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' has duplicate elements at index %d and %d", vParams.Name, first, i),
+			}
+		}
+
+		seen[key] = i
+	}
+
+	return nil
+}
+
+func mustBeMap(tagName string, structField reflect.StructField, fValue reflect.Value) {
+	if fValue.Kind() != reflect.Map {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a map", tagName, structField.Name))
+	}
+}
+
+func sortedMapKeys(fValue reflect.Value) []reflect.Value {
+	keys := fValue.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	return keys
+}
+
+func mapEntryName(paramName string, key reflect.Value) string {
+	return fmt.Sprintf("%s[%v]", paramName, key.Interface())
+}
+
+func checkValuePattern(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, pattern string) *ValidateError {
+	mustBeMap(TAG_FIELD_VALUE_PATTERN, structField, fValue)
+	if fValue.Type().Elem().Kind() != reflect.String {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The map values are not strings", TAG_FIELD_VALUE_PATTERN, structField.Name))
+	}
+
+	re := compilePattern(TAG_FIELD_VALUE_PATTERN, structField, pattern)
+	for _, key := range sortedMapKeys(fValue) {
+		if !re.MatchString(fValue.MapIndex(key).String()) {
+			entryName := mapEntryName(vParams.Name, key)
+			return &ValidateError{
+				ParamName:     entryName,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' does not match the pattern", entryName),
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkValueFormat(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, format string, o *options) *ValidateError {
+	mustBeMap(TAG_FIELD_VALUE_FORMAT, structField, fValue)
+
+	for _, key := range sortedMapKeys(fValue) {
+		entryParams := vParams
+		entryParams.Name = mapEntryName(vParams.Name, key)
+		if valErr := checkFormat(entryParams, structField, fValue.MapIndex(key), format, o); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+// checkHeadRule applies the rules given as headrule=rule1;rule2 to the
+// first element of a slice, which must not be empty. The transforms among
+// them rewrite the element.
+func checkHeadRule(vParams FieldValidationParams, structField reflect.StructField, fValue, structValue reflect.Value, tagRawVal string, o *options) *ValidateError {
+	mustBeSlice(TAG_FIELD_HEAD_RULE, structField, fValue)
+
+	if fValue.Len() == 0 {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_SHORT,
+			OriginalError: fmt.Errorf("Param '%s' is empty", vParams.Name),
+		}
+	}
+
+	headParams := decodeTagFields(strings.Split(tagRawVal, TAG_RULE_SEPARATOR))
+	headParams.Name = fmt.Sprintf("%s[0]", vParams.Name)
+	applyTransforms(headParams, structField, fValue.Index(0))
+
+	return validateFieldValue(headParams, structField, fValue.Index(0), structValue, o)
+}
+
+// checkDive applies the nested rules to every element, or to every element
+// but the first one when the head has its own headrule. The transforms among
+// them rewrite the elements.
+func checkDive(vParams FieldValidationParams, structField reflect.StructField, fValue, structValue reflect.Value, tagRawVal string, o *options) *ValidateError {
+	mustBeSlice(TAG_FIELD_DIVE, structField, fValue)
+
+	elemParams := decodeTagFields(strings.Split(tagRawVal, TAG_RULE_SEPARATOR))
+
+	start := 0
+	if _, ok := vParams.Fields[TAG_FIELD_HEAD_RULE]; ok {
+		start = 1
+	}
+
+	for i := start; i < fValue.Len(); i++ {
+		elemParams.Name = fmt.Sprintf("%s[%d]", vParams.Name, i)
+		applyTransforms(elemParams, structField, fValue.Index(i))
+
+		if valErr := validateFieldValue(elemParams, structField, fValue.Index(i), structValue, o); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+func isNillableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+
+	return false
+}
+
+// checkNonNilElems reports the first nil element of a slice or array, or the
+// nil value of the smallest key of a map.
+func checkNonNilElems(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	switch fValue.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a slice, array or map", TAG_FIELD_NON_NIL_ELEMS, structField.Name))
+	}
+
+	if !isNillableKind(fValue.Type().Elem().Kind()) {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"Its elements cannot be nil", TAG_FIELD_NON_NIL_ELEMS, structField.Name))
+	}
+
+	var nilAt []string
+	if fValue.Kind() == reflect.Map {
+		iter := fValue.MapRange()
+		for iter.Next() {
+			if iter.Value().IsNil() {
+				nilAt = append(nilAt, fmt.Sprint(iter.Key().Interface()))
+			}
+		}
+		sort.Strings(nilAt)
+	} else {
+		for i := 0; i < fValue.Len(); i++ {
+			if fValue.Index(i).IsNil() {
+				nilAt = append(nilAt, strconv.Itoa(i))
+				break
+			}
+		}
+	}
+
+	if len(nilAt) == 0 {
+		return nil
+	}
+
+	name := fmt.Sprintf("%s[%s]", vParams.Name, nilAt[0])
+	return &ValidateError{
+		ParamName:     name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is null", name),
+	}
+}
+
+// checkMonotonic requires the inner param of the struct elements to strictly
+// increase along the slice. Numbers, strings and times are supported.
+func checkMonotonic(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, param string) *ValidateError {
+	mustBeSlice(TAG_FIELD_MONOTONIC, structField, fValue)
+
+	var prev reflect.Value
+	for i := 0; i < fValue.Len(); i++ {
+		cur, ok := elemParam(fValue.Index(i), param)
+		if !ok {
+			continue
+		}
+
+		if prev.IsValid() && !increases(structField, prev, cur) {
+			name := fmt.Sprintf("%s[%d].%s", vParams.Name, i, param)
+			return &ValidateError{
+				ParamName:     name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' does not increase (%v after %v)", name, cur.Interface(), prev.Interface()),
+			}
+		}
+
+		prev = cur
+	}
+
+	return nil
+}
+
+func increases(structField reflect.StructField, prev, cur reflect.Value) bool {
+	if prev.Type() == timeType {
+		return cur.Interface().(time.Time).After(prev.Interface().(time.Time))
+	}
+
+	if prev.Kind() == reflect.String {
+		return cur.String() > prev.String()
+	}
+
+	greater, ok := exceeds(cur, prev)
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The compared values are not numbers, strings or times", TAG_FIELD_MONOTONIC, structField.Name))
+	}
+
+	return greater
+}
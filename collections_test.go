@@ -0,0 +1,272 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	RegisterKeyFunc("testemail", func(elem interface{}) string {
+		return strings.ToLower(strings.TrimSpace(elem.(string)))
+	})
+}
+
+func TestSumBounds(t *testing.T) {
+	type cart struct {
+		Prices []float64 `validate:"name=prices,sumMin=1,sumMax=100"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"prices":[10,20.5,69.5]}`, nil},
+		{`{"prices":[60,40.5]}`, &ValidateError{ParamName: "prices", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"prices":[0.5]}`, &ValidateError{ParamName: "prices", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out cart
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestMapValues(t *testing.T) {
+	type labels struct {
+		Tags map[string]string `validate:"name=tags,minvalues=1,maxvalues=2"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"tags":{"a":"1","b":"2"}}`, nil},
+		{`{"tags":{"a":"1","b":"2","c":"3"}}`, &ValidateError{ParamName: "tags", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"tags":{}}`, &ValidateError{ParamName: "tags", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out labels
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestContiguous(t *testing.T) {
+	type pages struct {
+		Numbers []int  `validate:"name=numbers,contiguous"`
+		Offsets []uint `validate:"name=offsets,contiguous"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"numbers":[-1,0,1,2],"offsets":[4,5,6]}`, nil},
+		{`{"numbers":[1,2,4]}`, &ValidateError{ParamName: "numbers", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"numbers":[2,1]}`, &ValidateError{ParamName: "numbers", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"offsets":[5,4]}`, &ValidateError{ParamName: "offsets", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"numbers":[9223372036854775807,-9223372036854775808]}`, &ValidateError{ParamName: "numbers", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"offsets":[18446744073709551615,0]}`, &ValidateError{ParamName: "offsets", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out pages
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestUniqueBy(t *testing.T) {
+	type invite struct {
+		Emails []string `validate:"name=emails,uniqueby=testemail"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"emails":["a@example.com","b@example.com"]}`, nil},
+		{`{"emails":["a@example.com"," A@Example.com"]}`, &ValidateError{ParamName: "emails", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out invite
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestMapValueRules(t *testing.T) {
+	type settings struct {
+		Colors map[string]string `validate:"name=colors,valuepattern=^#[0-9a-f]{6}$"`
+		Ports  map[string]int    `validate:"name=ports,valueformat=port"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"colors":{"bg":"#ffffff","fg":"#000000"},"ports":{"http":80,"https":443}}`, nil},
+		{`{"colors":{"bg":"#ffffff","fg":"black"}}`, &ValidateError{ParamName: "colors[fg]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"ports":{"http":80,"admin":70000}}`, &ValidateError{ParamName: "ports[admin]", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out settings
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestHeadRule(t *testing.T) {
+	type route struct {
+		Stops []string `validate:"name=stops,headrule=oneof=depot;minLen=1"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"stops":["depot","a","b"]}`, nil},
+		{`{"stops":["a","depot"]}`, &ValidateError{ParamName: "stops[0]", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"stops":[]}`, &ValidateError{ParamName: "stops", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out route
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestValueSum(t *testing.T) {
+	type portfolio struct {
+		Weights map[string]float64 `validate:"name=weights,valuesum=1:0.001"`
+		Shares  map[string]int     `validate:"name=shares,valuesum=100"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"weights":{"a":0.5,"b":0.3,"c":0.2}}`, nil},
+		{`{"weights":{"a":0.3333,"b":0.3333,"c":0.3334}}`, nil},
+		{`{"weights":{"a":0.5,"b":0.4}}`, &ValidateError{ParamName: "weights", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"shares":{"a":60,"b":40}}`, nil},
+		{`{"shares":{"a":60,"b":39}}`, &ValidateError{ParamName: "shares", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out portfolio
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestDive(t *testing.T) {
+	type batch struct {
+		IDs  []int    `validate:"name=ids,maxLen=3,dive,min=1"`
+		Tags []string `validate:"name=tags,dive,minLen=2,maxLen=4"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"ids":[1,2,3],"tags":["go","json"]}`, nil},
+		{`{"ids":[1,2,0]}`, &ValidateError{ParamName: "ids[2]", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"ids":[1,2,3,4]}`, &ValidateError{ParamName: "ids", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"tags":["go","x"]}`, &ValidateError{ParamName: "tags[1]", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{`{"tags":["golang"]}`, &ValidateError{ParamName: "tags[0]", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out batch
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	type event struct {
+		Seq int       `validate:"name=seq"`
+		At  time.Time `validate:"name=at"`
+	}
+
+	type stream struct {
+		BySeq  []event `validate:"name=bySeq,monotonic=seq"`
+		ByTime []event `validate:"name=byTime,monotonic=at"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"bySeq":[{"seq":1},{"seq":2},{"seq":5}]}`, nil},
+		{`{"bySeq":[{"seq":1},{"seq":3},{"seq":3}]}`, &ValidateError{ParamName: "bySeq[2].seq", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"bySeq":[{"seq":2},{"seq":1}]}`, &ValidateError{ParamName: "bySeq[1].seq", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"byTime":[{"at":"2024-01-09T10:00:00Z"},{"at":"2024-01-09T11:00:00+02:00"}]}`, &ValidateError{ParamName: "byTime[1].at", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"byTime":[{"at":"2024-01-09T10:00:00Z"},{"at":"2024-01-09T11:00:00Z"}]}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out stream
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestNonNilElems(t *testing.T) {
+	type batch struct {
+		Items  []*string          `validate:"name=items,nonnilelems"`
+		Labels map[string]*string `validate:"name=labels,nonnilelems"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"items":["a","b"],"labels":{"x":"1"}}`, nil},
+		{`{"items":[]}`, nil},
+		{`{"items":["a",null,null]}`, &ValidateError{ParamName: "items[1]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"labels":{"y":null,"x":null}}`, &ValidateError{ParamName: "labels[x]", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out batch
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestNonNilElemsNotNillable(t *testing.T) {
+	type batch struct {
+		Items []string `validate:"name=items,nonnilelems"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("nonnilelems on a []string did not panic")
+		}
+	}()
+
+	var out batch
+	Validate(decodeInput(t, `{"items":["a"]}`), &out)
+}
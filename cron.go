@@ -0,0 +1,112 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type cronField struct {
+	min, max int
+	names    []string
+	anyMark  bool
+}
+
+var (
+	cronSeconds  = cronField{min: 0, max: 59}
+	cronMinutes  = cronField{min: 0, max: 59}
+	cronHours    = cronField{min: 0, max: 23}
+	cronDays     = cronField{min: 1, max: 31, anyMark: true}
+	cronMonths   = cronField{min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	cronWeekdays = cronField{min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}, anyMark: true}
+)
+
+// checkCron accepts the standard 5-field cron syntax and the 6-field one
+// starting with seconds. Each field is a list of `*`, values or ranges, all
+// optionally followed by a /step.
+func checkCron(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	fields := strings.Fields(fValue.String())
+	layout := []cronField{cronMinutes, cronHours, cronDays, cronMonths, cronWeekdays}
+	if len(fields) == 6 {
+		layout = append([]cronField{cronSeconds}, layout...)
+	}
+
+	if len(fields) == len(layout) {
+		valid := true
+		for i, f := range fields {
+			if !layout[i].valid(f) {
+				valid = false
+				break
+			}
+		}
+
+		if valid {
+			return nil
+		}
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid cron expression", vParams.Name),
+	}
+}
+
+func (cf cronField) valid(field string) bool {
+	if cf.anyMark && field == "?" {
+		return true
+	}
+
+	for _, item := range strings.Split(field, ",") {
+		rangePart := item
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			step, err := strconv.Atoi(item[i+1:])
+			if err != nil || step <= 0 {
+				return false
+			}
+
+			rangePart = item[:i]
+		}
+
+		if rangePart == "*" {
+			continue
+		}
+
+		bounds := strings.SplitN(rangePart, "-", 2)
+		lo, ok := cf.value(bounds[0])
+		if !ok {
+			return false
+		}
+
+		if len(bounds) == 2 {
+			hi, ok := cf.value(bounds[1])
+			if !ok || hi < lo {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (cf cronField) value(s string) (int, bool) {
+	for i, name := range cf.names {
+		if strings.EqualFold(s, name) {
+			return cf.min + i, true
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < cf.min || v > cf.max {
+		return 0, false
+	}
+
+	return v, true
+}
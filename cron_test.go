@@ -0,0 +1,34 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestFormatCron(t *testing.T) {
+	type job struct {
+		Schedule string `validate:"name=schedule,format=cron"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"schedule":"*/5 * * * *"}`, nil},
+		{`{"schedule":"0 30 9 * * MON-FRI"}`, nil},
+		{`{"schedule":"0 0 1,15 JAN-JUN ?"}`, nil},
+		{`{"schedule":"60 * * * *"}`, &ValidateError{ParamName: "schedule", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"schedule":"* * * *"}`, &ValidateError{ParamName: "schedule", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"schedule":"*/0 * * * *"}`, &ValidateError{ParamName: "schedule", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"schedule":"0 0 0 ? * MON"}`, nil},
+		{`{"schedule":"0 ? * * *"}`, &ValidateError{ParamName: "schedule", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out job
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
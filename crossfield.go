@@ -0,0 +1,374 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// presentField is a field which was provided in the input or got its
+// default. Rules referring to other params are checked once all of them
+// are decoded, since the referenced field may come later in the struct.
+type presentField struct {
+	name   string
+	params FieldValidationParams
+	field  reflect.StructField
+	value  reflect.Value
+}
+
+func findPresent(present []presentField, name string) (reflect.Value, bool) {
+	for _, pf := range present {
+		if pf.name == name {
+			return pf.value, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func checkCrossFields(present []presentField, o *options) *ValidateError {
+	for _, pf := range present {
+		// Sorted like in validateFieldValue, the first error is deterministic
+		tagNames := make([]string, 0, len(pf.params.Fields))
+		for tagName := range pf.params.Fields {
+			tagNames = append(tagNames, tagName)
+		}
+		sort.Strings(tagNames)
+
+		for _, tagName := range tagNames {
+			tagRawVal := pf.params.Fields[tagName]
+			var valErr *ValidateError
+			switch tagName {
+			case TAG_FIELD_PHONE_REGION:
+				valErr = checkPhoneRegion(pf, present, tagRawVal)
+			case TAG_FIELD_HMAC:
+				valErr = checkHMAC(pf, present, tagRawVal)
+			case TAG_FIELD_MONEY_SCALE:
+				valErr = checkMoneyScale(pf, present, tagRawVal)
+			case TAG_FIELD_DISJOINT:
+				valErr = checkDisjoint(pf, present, tagRawVal)
+			case TAG_FIELD_INDEX_INTO:
+				valErr = checkIndexInto(pf, present, tagRawVal)
+			case TAG_FIELD_REQUIRES:
+				valErr = checkRequires(pf, present, tagRawVal)
+			case TAG_FIELD_CONVERT_TO_MIN, TAG_FIELD_CONVERT_TO_MAX:
+				valErr = checkConvertedBound(pf, present, tagName, tagRawVal)
+			case TAG_FIELD_GT_FIELD, TAG_FIELD_LT_FIELD:
+				valErr = checkFieldOrder(pf, present, tagName, tagRawVal)
+			case TAG_FIELD_PERMUTATION_OF:
+				valErr = checkPermutationOf(pf, present, tagRawVal)
+			}
+
+			if valErr = o.fail(valErr); valErr != nil {
+				return valErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRequiredIf reports the absent fields tagged required_if=param value
+// whose condition holds, i.e. param is present and its value formats to
+// value.
+func checkRequiredIf(absent, present []presentField, o *options) *ValidateError {
+	for _, pf := range absent {
+		parts := strings.SplitN(pf.params.Fields[TAG_FIELD_REQUIRED_IF], " ", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as 'param value'", TAG_FIELD_REQUIRED_IF, pf.field.Name))
+		}
+
+		other, ok := findPresent(present, parts[0])
+		if !ok || fmt.Sprint(other.Interface()) != parts[1] {
+			continue
+		}
+
+		if valErr := o.fail(&ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+			OriginalError: fmt.Errorf("Param '%s' is required when '%s' is %s", pf.params.Name, parts[0], parts[1]),
+		}); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+// checkFieldOrder compares pf to otherParam, both times or both numbers. The
+// times are compared in UTC, whatever offsets they were given with.
+func checkFieldOrder(pf presentField, present []presentField, tagName, otherParam string) *ValidateError {
+	other, ok := findPresent(present, otherParam)
+	if !ok {
+		return nil
+	}
+
+	var cmp int
+	if pf.value.Type() == timeType && other.Type() == timeType {
+		v, bound := pf.value.Interface().(time.Time).UTC(), other.Interface().(time.Time).UTC()
+		switch {
+		case v.Before(bound):
+			cmp = -1
+		case v.After(bound):
+			cmp = 1
+		}
+	} else {
+		v, ok := numericAsFloat(pf.value)
+		bound, okOther := numericAsFloat(other)
+		if !ok || !okOther {
+			panic(fmt.Sprintf("Tag '%s' of field '%s' compares it to '%s', they are not both times or numbers",
+				tagName, pf.field.Name, otherParam))
+		}
+
+		switch {
+		case v < bound:
+			cmp = -1
+		case v > bound:
+			cmp = 1
+		}
+	}
+
+	if tagName == TAG_FIELD_GT_FIELD && cmp <= 0 {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_SMALL,
+			OriginalError: fmt.Errorf("Param '%s' is not greater than '%s'", pf.params.Name, otherParam),
+		}
+	}
+
+	if tagName == TAG_FIELD_LT_FIELD && cmp >= 0 {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_BIG,
+			OriginalError: fmt.Errorf("Param '%s' is not less than '%s'", pf.params.Name, otherParam),
+		}
+	}
+
+	return nil
+}
+
+// checkRequires makes otherParam required whenever pf is present. The error
+// is reported against otherParam.
+func checkRequires(pf presentField, present []presentField, otherParam string) *ValidateError {
+	if _, ok := findPresent(present, otherParam); ok {
+		return nil
+	}
+
+	name := strings.TrimSuffix(pf.params.Name, pf.name) + otherParam
+	return &ValidateError{
+		ParamName:     name,
+		Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+		OriginalError: fmt.Errorf("Param '%s' is required with '%s'", name, pf.params.Name),
+	}
+}
+
+var e164Regexp = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+var regionCallingCodes = map[string]string{
+	"AU": "61",
+	"BR": "55",
+	"CA": "1",
+	"CH": "41",
+	"CN": "86",
+	"DE": "49",
+	"ES": "34",
+	"FR": "33",
+	"GB": "44",
+	"IN": "91",
+	"IT": "39",
+	"JP": "81",
+	"KR": "82",
+	"MX": "52",
+	"NL": "31",
+	"PL": "48",
+	"RU": "7",
+	"SE": "46",
+	"UA": "380",
+	"US": "1",
+}
+
+// checkPhoneRegion expects an E.164 number whose calling code matches the
+// region held by the referenced param.
+func checkPhoneRegion(pf presentField, present []presentField, regionParam string) *ValidateError {
+	mustBeString(TAG_FIELD_PHONE_REGION, pf.field, pf.value)
+
+	phone := pf.value.String()
+	if !e164Regexp.MatchString(phone) {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not an E.164 phone number", pf.params.Name),
+		}
+	}
+
+	regionValue, ok := findPresent(present, regionParam)
+	if !ok {
+		return nil
+	}
+
+	if regionValue.Kind() != reflect.String {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' refers to '%s' which is not a string",
+			TAG_FIELD_PHONE_REGION, pf.field.Name, regionParam))
+	}
+
+	region := strings.ToUpper(regionValue.String())
+	callingCode, ok := regionCallingCodes[region]
+	if !ok {
+		return &ValidateError{
+			ParamName:     regionParam,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a supported region", regionParam),
+		}
+	}
+
+	if !strings.HasPrefix(phone, "+"+callingCode) {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a phone number of region %s", pf.params.Name, region),
+		}
+	}
+
+	return nil
+}
+
+// refSlice returns the referenced param, which has to be a slice or array
+// like the field referring to it.
+func refSlice(tagName string, pf presentField, present []presentField, otherParam string) (reflect.Value, bool) {
+	mustBeSlice(tagName, pf.field, pf.value)
+
+	other, ok := findPresent(present, otherParam)
+	if !ok {
+		return other, false
+	}
+
+	if other.Kind() != reflect.Slice && other.Kind() != reflect.Array {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' refers to '%s' which is not a slice or array",
+			tagName, pf.field.Name, otherParam))
+	}
+
+	return other, true
+}
+
+func checkDisjoint(pf presentField, present []presentField, otherParam string) *ValidateError {
+	other, ok := refSlice(TAG_FIELD_DISJOINT, pf, present, otherParam)
+	if !ok {
+		return nil
+	}
+
+	if !pf.value.Type().Elem().Comparable() || !other.Type().Elem().Comparable() {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The elements are not comparable", TAG_FIELD_DISJOINT, pf.field.Name))
+	}
+
+	seen := make(map[interface{}]bool, other.Len())
+	for i := 0; i < other.Len(); i++ {
+		seen[other.Index(i).Interface()] = true
+	}
+
+	for i := 0; i < pf.value.Len(); i++ {
+		elem := pf.value.Index(i).Interface()
+		if seen[elem] {
+			return &ValidateError{
+				ParamName:     pf.params.Name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' shares '%v' with '%s'", pf.params.Name, elem, otherParam),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkPermutationOf requires the slice to hold the same elements as the
+// other one, with the same multiplicity, in any order.
+func checkPermutationOf(pf presentField, present []presentField, otherParam string) *ValidateError {
+	other, ok := refSlice(TAG_FIELD_PERMUTATION_OF, pf, present, otherParam)
+	if !ok {
+		return nil
+	}
+
+	if !pf.value.Type().Elem().Comparable() || !other.Type().Elem().Comparable() {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The elements are not comparable", TAG_FIELD_PERMUTATION_OF, pf.field.Name))
+	}
+
+	counts := make(map[interface{}]int, other.Len())
+	for i := 0; i < other.Len(); i++ {
+		counts[other.Index(i).Interface()]++
+	}
+
+	var extra, missing []string
+	for i := 0; i < pf.value.Len(); i++ {
+		elem := pf.value.Index(i).Interface()
+		if counts[elem] == 0 {
+			extra = append(extra, fmt.Sprint(elem))
+			continue
+		}
+
+		counts[elem]--
+	}
+
+	for i := 0; i < other.Len(); i++ {
+		elem := other.Index(i).Interface()
+		if counts[elem] > 0 {
+			missing = append(missing, fmt.Sprint(elem))
+			counts[elem]--
+		}
+	}
+
+	if len(extra) == 0 && len(missing) == 0 {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName: pf.params.Name,
+		Code:      VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a permutation of '%s' (missing: [%s], extra: [%s])",
+			pf.params.Name, otherParam, strings.Join(missing, ", "), strings.Join(extra, ", ")),
+	}
+}
+
+// checkIndexInto requires every element to be an index into the other slice.
+// A missing other param is handled as an empty slice.
+func checkIndexInto(pf presentField, present []presentField, otherParam string) *ValidateError {
+	other, ok := refSlice(TAG_FIELD_INDEX_INTO, pf, present, otherParam)
+	otherLen := 0
+	if ok {
+		otherLen = other.Len()
+	}
+
+	elemKind := pf.value.Type().Elem().Kind()
+	if !isIntKind(elemKind) && !isUintKind(elemKind) {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The elements are not integers", TAG_FIELD_INDEX_INTO, pf.field.Name))
+	}
+
+	for i := 0; i < pf.value.Len(); i++ {
+		elem := pf.value.Index(i)
+		inRange := false
+		if isIntKind(elemKind) {
+			inRange = elem.Int() >= 0 && elem.Int() < int64(otherLen)
+		} else {
+			inRange = elem.Uint() < uint64(otherLen)
+		}
+
+		if !inRange {
+			name := fmt.Sprintf("%s[%d]", pf.params.Name, i)
+			return &ValidateError{
+				ParamName:     name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' is not a valid index into '%s' (%v)", name, otherParam, elem.Interface()),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCrossFieldOrder(t *testing.T) {
+	type payload struct {
+		Value int    `validate:"name=value,ltfield=low,gtfield=high,requires=note"`
+		Low   int    `validate:"name=low"`
+		High  int    `validate:"name=high"`
+		Note  string `validate:"name=note"`
+	}
+
+	for i := 0; i < 50; i++ {
+		var out payload
+		checkError(t, Validate(decodeInput(t, `{"value":5,"low":1,"high":9}`), &out),
+			&ValidateError{ParamName: "value", Code: VALIDATE_ERR_CODE_TOO_SMALL})
+	}
+}
+
+func TestPhoneRegion(t *testing.T) {
+	type contact struct {
+		Region string `validate:"name=region"`
+		Phone  string `validate:"name=phone,phoneregion=region"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"region":"UA","phone":"+380441234567"}`, nil},
+		{`{"region":"us","phone":"+12025550123"}`, nil},
+		{`{"phone":"+380441234567"}`, nil},
+		{`{"region":"PL","phone":"+380441234567"}`, &ValidateError{ParamName: "phone", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"region":"PL","phone":"0441234567"}`, &ValidateError{ParamName: "phone", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"region":"XX","phone":"+380441234567"}`, &ValidateError{ParamName: "region", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out contact
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestDisjoint(t *testing.T) {
+	type acl struct {
+		Allow []string `validate:"name=allow,disjoint=deny"`
+		Deny  []string `validate:"name=deny"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"allow":["read","write"],"deny":["delete"]}`, nil},
+		{`{"allow":["read"]}`, nil},
+		{`{"allow":["read","write"],"deny":["write"]}`, &ValidateError{ParamName: "allow", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out acl
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestIndexInto(t *testing.T) {
+	type graph struct {
+		Nodes []string `validate:"name=nodes"`
+		Edges []int    `validate:"name=edges,indexinto=nodes"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"nodes":["a","b","c"],"edges":[0,2,1]}`, nil},
+		{`{"nodes":["a","b"],"edges":[0,2]}`, &ValidateError{ParamName: "edges[1]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"nodes":["a","b"],"edges":[-1]}`, &ValidateError{ParamName: "edges[0]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"edges":[0]}`, &ValidateError{ParamName: "edges[0]", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out graph
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestRequires(t *testing.T) {
+	type shipping struct {
+		Street string `validate:"name=street,requires=zip"`
+		Zip    string `validate:"name=zip"`
+	}
+
+	type order struct {
+		Shipping shipping `validate:"name=shipping"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"shipping":{"street":"Main St 1","zip":"01001"}}`, nil},
+		{`{"shipping":{"zip":"01001"}}`, nil},
+		{`{"shipping":{}}`, nil},
+		{`{"shipping":{"street":"Main St 1"}}`, &ValidateError{ParamName: "shipping.zip", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out order
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	type address struct {
+		State   string `validate:"name=state,required_if=country US"`
+		Country string `validate:"name=country"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"country":"US","state":"CA"}`, nil},
+		{`{"country":"UA"}`, nil},
+		{`{}`, nil},
+		{`{"country":"US"}`, &ValidateError{ParamName: "state", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out address
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	out := address{Country: "US"}
+	checkError(t, ValidateStruct(&out), &ValidateError{ParamName: "state", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM})
+}
+
+func TestFieldOrder(t *testing.T) {
+	type meeting struct {
+		Start time.Time `validate:"name=start,ltfield=end"`
+		End   time.Time `validate:"name=end,gtfield=start"`
+	}
+
+	type bid struct {
+		Min float64 `validate:"name=min"`
+		Max int     `validate:"name=max,gtfield=min"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		// 10:00 in +05:00 is 05:00 UTC, before 08:00 UTC despite the later clock
+		{`{"start":"2024-01-01T10:00:00+05:00","end":"2024-01-01T08:00:00Z"}`, nil},
+		{`{"start":"2024-01-01T08:00:00Z","end":"2024-01-01T10:00:00+05:00"}`, &ValidateError{ParamName: "start", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"start":"2024-01-01T13:00:00+05:00","end":"2024-01-01T08:00:00Z"}`, &ValidateError{ParamName: "start", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"start":"2024-01-01T08:00:00Z"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out meeting
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	var out bid
+	checkError(t, Validate(decodeInput(t, `{"min":2.5,"max":3}`), &out), nil)
+	checkError(t, Validate(decodeInput(t, `{"min":3.5,"max":3}`), &out),
+		&ValidateError{ParamName: "max", Code: VALIDATE_ERR_CODE_TOO_SMALL})
+}
+
+func TestPermutationOf(t *testing.T) {
+	type ranking struct {
+		Candidates []string `validate:"name=candidates"`
+		Order      []string `validate:"name=order,permutationof=candidates"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"candidates":["a","b","c"],"order":["c","a","b"]}`, nil},
+		{`{"candidates":["a","a","b"],"order":["a","b","a"]}`, nil},
+		{`{"candidates":["a","b"]}`, nil},
+		{`{"candidates":["a","b","c"],"order":["c","a","a"]}`, &ValidateError{ParamName: "order", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"candidates":["a","b","c"],"order":["c","a"]}`, &ValidateError{ParamName: "order", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"candidates":["a","b"],"order":["a","b","d"]}`, &ValidateError{ParamName: "order", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out ranking
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
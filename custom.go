@@ -0,0 +1,55 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var validators = struct {
+	sync.RWMutex
+	m map[string]func(value interface{}) error
+}{
+	m: make(map[string]func(value interface{}) error),
+}
+
+// RegisterValidator makes fn available as custom=name. It is given the
+// decoded value of the field and the validation fails if it returns an
+// error. It panics if the name is already registered.
+func RegisterValidator(name string, fn func(value interface{}) error) {
+	if name == "" || fn == nil {
+		panic("Validator must have a name and a function")
+	}
+
+	validators.Lock()
+	defer validators.Unlock()
+
+	if _, ok := validators.m[name]; ok {
+		panic(fmt.Sprintf("Validator '%s' already registered", name))
+	}
+
+	validators.m[name] = fn
+}
+
+func checkCustom(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, name string) *ValidateError {
+	validators.RLock()
+	fn, ok := validators.m[name]
+	validators.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Unknown validator '%s' on field '%s'", name, structField.Name))
+	}
+
+	if err := fn(fValue.Interface()); err != nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a valid %s: %s", vParams.Name, name, err.Error()),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"errors"
+	"testing"
+)
+
+func init() {
+	RegisterValidator("testeven", func(value interface{}) error {
+		if value.(int)%2 != 0 {
+			return errors.New("odd number")
+		}
+		return nil
+	})
+}
+
+func TestCustom(t *testing.T) {
+	type pair struct {
+		Count int `validate:"name=count,custom=testeven"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"count":4}`, nil},
+		{`{"count":0}`, nil},
+		{`{"count":3}`, &ValidateError{ParamName: "count", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out pair
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestCustomUnknown(t *testing.T) {
+	type pair struct {
+		Count int `validate:"name=count,custom=testmissing"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("an unknown validator did not panic")
+		}
+	}()
+
+	var out pair
+	Validate(decodeInput(t, `{"count":1}`), &out)
+}
+
+func TestRegisterValidatorTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a validator twice did not panic")
+		}
+	}()
+
+	RegisterValidator("testeven", func(value interface{}) error { return nil })
+}
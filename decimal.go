@@ -0,0 +1,114 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var decimalRegexp = regexp.MustCompile(`^[+-]?([0-9]+)(\.([0-9]+))?$`)
+
+var canonicalIntRegexp = regexp.MustCompile(`^(0|-?[1-9][0-9]*)$`)
+
+// checkDecimal validates a decimal string against DECIMAL(p,s) column rules
+// written as decimal=p:s: at most s fractional digits and at most p-s digits
+// before the point. The string is never converted to a float.
+func checkDecimal(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	mustBeString(TAG_FIELD_DECIMAL, structField, fValue)
+
+	precision, scale := parseDecimalTag(structField, tagRawVal)
+
+	m := decimalRegexp.FindStringSubmatch(fValue.String())
+	if m == nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a decimal number", vParams.Name),
+		}
+	}
+
+	intDigits := len(strings.TrimLeft(m[1], "0"))
+	fracDigits := len(m[3])
+	if fracDigits > scale || intDigits > precision-scale {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' does not fit DECIMAL(%d,%d)", vParams.Name, precision, scale),
+		}
+	}
+
+	return nil
+}
+
+func parseDecimalTag(structField reflect.StructField, tagRawVal string) (int, int) {
+	parts := strings.SplitN(tagRawVal, ":", 2)
+	if len(parts) == 2 {
+		precision, errP := strconv.ParseUint(parts[0], 10, 32)
+		scale, errS := strconv.ParseUint(parts[1], 10, 32)
+		if errP == nil && errS == nil && precision > 0 && scale <= precision {
+			return int(precision), int(scale)
+		}
+	}
+
+	panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as precision:scale", TAG_FIELD_DECIMAL, structField.Name))
+}
+
+// checkStrictNumeric accepts integers in their canonical form only: no plus
+// sign and no leading zeros.
+func checkStrictNumeric(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_STRICT_NUMERIC, structField, fValue)
+
+	if canonicalIntRegexp.MatchString(fValue.String()) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a canonical integer", vParams.Name),
+	}
+}
+
+// checkExactNumber compares a decoded float with the JSON integer it was
+// decoded from, e.g. 9007199254740993 does not fit a float64 exactly.
+// Numbers with a fraction or an exponent are not compared, 0.1 is never
+// exact in binary.
+func checkExactNumber(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, raw json.RawMessage) *ValidateError {
+	if fValue.Kind() == reflect.Ptr {
+		if fValue.IsNil() {
+			return nil
+		}
+
+		fValue = fValue.Elem()
+	}
+
+	if fValue.Kind() != reflect.Float32 && fValue.Kind() != reflect.Float64 {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a float", TAG_FIELD_EXACT_INT, structField.Name))
+	}
+
+	literal := string(bytes.TrimSpace(raw))
+	if strings.ContainsAny(literal, ".eE") {
+		return nil
+	}
+
+	original, _, err := big.ParseFloat(literal, 10, 1024, big.ToNearestEven)
+	if err != nil || original.Cmp(big.NewFloat(fValue.Float())) != 0 {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: fmt.Errorf("Param '%s' cannot be represented exactly as %s", vParams.Name, fValue.Type()),
+		}
+	}
+
+	return nil
+}
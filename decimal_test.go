@@ -0,0 +1,81 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestDecimal(t *testing.T) {
+	type payment struct {
+		Amount string `validate:"name=amount,decimal=5:2"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"amount":"123.45"}`, nil},
+		{`{"amount":"-0.5"}`, nil},
+		{`{"amount":"000123"}`, nil},
+		{`{"amount":"1234.5"}`, &ValidateError{ParamName: "amount", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"amount":"1.234"}`, &ValidateError{ParamName: "amount", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"amount":"1e2"}`, &ValidateError{ParamName: "amount", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out payment
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestStrictNumeric(t *testing.T) {
+	type order struct {
+		Quantity string `validate:"name=quantity,strictnumeric"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"quantity":"42"}`, nil},
+		{`{"quantity":"0"}`, nil},
+		{`{"quantity":"-7"}`, nil},
+		{`{"quantity":"007"}`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"quantity":"+5"}`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"quantity":"-0"}`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out order
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestExactInt(t *testing.T) {
+	type payload struct {
+		F float64 `validate:"name=f,exactint"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"f":9007199254740992}`, nil},
+		{`{"f":9007199254740993}`, &ValidateError{ParamName: "f", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{`{"f":0.1}`, nil},
+		{`{"f":1e3}`, nil},
+		{`{"f":-42}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out payload
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	DEFAULT_FUNC_PREFIX = "@"
+	DEFAULT_FUNC_NOW    = "now"
+	DEFAULT_FUNC_UUID   = "uuid"
+)
+
+var defaultFuncs = struct {
+	sync.RWMutex
+	m map[string]func() interface{}
+}{
+	m: map[string]func() interface{}{
+		DEFAULT_FUNC_NOW:  func() interface{} { return now() },
+		DEFAULT_FUNC_UUID: func() interface{} { return newUUID() },
+	},
+}
+
+// RegisterDefaultFunc makes fn available as default=@name, fn is called for
+// every absent field to produce its default. A default naming no registered
+// function is a literal, e.g. default=@home, and a leading @@ stands for a
+// literal @. It panics if the name is already registered.
+func RegisterDefaultFunc(name string, fn func() interface{}) {
+	if name == "" || fn == nil {
+		panic("Default function must have a name and a function")
+	}
+
+	defaultFuncs.Lock()
+	defer defaultFuncs.Unlock()
+
+	if _, ok := defaultFuncs.m[name]; ok {
+		panic(fmt.Sprintf("Default function '%s' already registered", name))
+	}
+
+	defaultFuncs.m[name] = fn
+}
+
+// setDefaultFromFunc sets the field with the function rawValue refers to. It
+// returns false if rawValue does not name a registered function.
+func setDefaultFromFunc(field reflect.Value, rawValue string) bool {
+	if !strings.HasPrefix(rawValue, DEFAULT_FUNC_PREFIX) {
+		return false
+	}
+
+	name := strings.TrimPrefix(rawValue, DEFAULT_FUNC_PREFIX)
+
+	defaultFuncs.RLock()
+	fn, ok := defaultFuncs.m[name]
+	defaultFuncs.RUnlock()
+	if !ok {
+		return false
+	}
+
+	val := reflect.ValueOf(fn())
+	switch {
+	case !val.IsValid():
+		field.Set(reflect.Zero(field.Type()))
+	case val.Type().AssignableTo(field.Type()):
+		field.Set(val)
+	case val.Type().ConvertibleTo(field.Type()):
+		field.Set(val.Convert(field.Type()))
+	default:
+		panic(fmt.Sprintf("Default function '%s' returned %s which cannot be assigned to %s",
+			name, val.Type(), field.Type()))
+	}
+
+	return true
+}
+
+// newUUID returns a random (version 4) UUID in its canonical form.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("Unable to generate a UUID: %s", err.Error()))
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
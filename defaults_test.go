@@ -0,0 +1,39 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func init() {
+	RegisterDefaultFunc("testregion", func() interface{} { return "eu-west" })
+}
+
+func TestDefaultFunc(t *testing.T) {
+	type payload struct {
+		Region  string `validate:"name=region,default=@testregion"`
+		Home    string `validate:"name=home,default=@home"`
+		Escaped string `validate:"name=escaped,default=@@testregion"`
+		ID      string `validate:"name=id,default=@uuid"`
+	}
+
+	var out payload
+	checkError(t, Validate(decodeInput(t, `{}`), &out), nil)
+
+	if out.Region != "eu-west" {
+		t.Errorf("region = %q, want the registered default", out.Region)
+	}
+
+	if out.Home != "@home" {
+		t.Errorf("home = %q, want the literal default", out.Home)
+	}
+
+	if out.Escaped != "@testregion" {
+		t.Errorf("escaped = %q, want the unescaped literal", out.Escaped)
+	}
+
+	if !uuidRegexp.MatchString(out.ID) {
+		t.Errorf("id = %q, want a UUID", out.ID)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var discriminators = struct {
+	sync.RWMutex
+	m map[string]map[string]reflect.Type
+}{
+	m: make(map[string]map[string]reflect.Type),
+}
+
+// RegisterDiscriminator maps the values of the discriminator param to the
+// struct types validated by discriminator=param fields. Such a field is an
+// interface{} decoded from an object holding the param, e.g.
+// {"type": "a", ...} is decoded and validated as types["a"]. It panics if the
+// discriminator is already registered.
+func RegisterDiscriminator(param string, types map[string]interface{}) {
+	if param == "" || len(types) == 0 {
+		panic("Discriminator must have a param and types")
+	}
+
+	m := make(map[string]reflect.Type, len(types))
+	for value, structType := range types {
+		t := structTypeOf(structType)
+		if t == nil {
+			panic(fmt.Sprintf("Discriminator '%s' maps '%s' to a non-struct type", param, value))
+		}
+
+		m[value] = t
+	}
+
+	discriminators.Lock()
+	defer discriminators.Unlock()
+
+	if _, ok := discriminators.m[param]; ok {
+		panic(fmt.Sprintf("Discriminator '%s' already registered", param))
+	}
+
+	discriminators.m[param] = m
+}
+
+func lookupDiscriminator(structField reflect.StructField, fValue reflect.Value, param string) map[string]reflect.Type {
+	if fValue.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not an interface", TAG_FIELD_DISCRIMINATOR, structField.Name))
+	}
+
+	discriminators.RLock()
+	types, ok := discriminators.m[param]
+	discriminators.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' refers to unknown discriminator '%s'",
+			TAG_FIELD_DISCRIMINATOR, structField.Name, param))
+	}
+
+	return types
+}
+
+func decodeDiscriminated(raw json.RawMessage, vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, depth int, o *options) *ValidateError {
+	param := vParams.Fields[TAG_FIELD_DISCRIMINATOR]
+	types := lookupDiscriminator(structField, fValue, param)
+	if depth >= VALIDATE_MAX_DEPTH {
+		return tooDeep(vParams.Name)
+	}
+
+	var data map[string]*json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	if data == nil {
+		fValue.Set(reflect.Zero(fValue.Type()))
+		return nil
+	}
+
+	discName := vParams.Name + "." + param
+	discRaw, ok := data[param]
+	if !ok || discRaw == nil {
+		return &ValidateError{
+			ParamName:     discName,
+			Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+			OriginalError: fmt.Errorf("Param '%s' is required", discName),
+		}
+	}
+
+	var disc string
+	if err := json.Unmarshal(*discRaw, &disc); err != nil {
+		return &ValidateError{
+			ParamName:     discName,
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	t, ok := types[disc]
+	if !ok {
+		return &ValidateError{
+			ParamName:     discName,
+			Code:          VALIDATE_ERR_CODE_NOT_ALLOWED,
+			OriginalError: fmt.Errorf("Param '%s' has unknown value '%s'", discName, disc),
+		}
+	}
+
+	elem := reflect.New(t).Elem()
+	valErr := validateInput(data, elem, vParams.Name+".", depth+1, o)
+	fValue.Set(elem)
+
+	return valErr
+}
+
+func validatePopulatedDiscriminated(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, depth int, o *options) *ValidateError {
+	param := vParams.Fields[TAG_FIELD_DISCRIMINATOR]
+	types := lookupDiscriminator(structField, fValue, param)
+	if depth >= VALIDATE_MAX_DEPTH {
+		return tooDeep(vParams.Name)
+	}
+
+	elem := fValue.Elem()
+	isPtr := elem.Kind() == reflect.Ptr
+	if isPtr {
+		if elem.IsNil() {
+			return nil
+		}
+
+		elem = elem.Elem()
+	}
+
+	for _, t := range types {
+		if elem.Type() != t {
+			continue
+		}
+
+		if isPtr {
+			return validatePopulated(elem, vParams.Name+".", depth+1, o)
+		}
+
+		elemCopy := reflect.New(t).Elem()
+		elemCopy.Set(elem)
+		valErr := validatePopulated(elemCopy, vParams.Name+".", depth+1, o)
+		fValue.Set(elemCopy)
+
+		return valErr
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_NOT_ALLOWED,
+		OriginalError: fmt.Errorf("Param '%s' holds %s which discriminator '%s' does not map", vParams.Name, elem.Type(), param),
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+type testCircle struct {
+	Kind   string  `validate:"name=kind"`
+	Radius float64 `validate:"name=radius,required,max=100"`
+}
+
+type testRect struct {
+	Kind  string  `validate:"name=kind"`
+	Width float64 `validate:"name=width,required"`
+}
+
+func init() {
+	RegisterDiscriminator("kind", map[string]interface{}{
+		"circle": testCircle{},
+		"rect":   testRect{},
+	})
+}
+
+func TestDiscriminator(t *testing.T) {
+	type drawing struct {
+		Shape interface{} `validate:"name=shape,discriminator=kind"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"shape":{"kind":"circle","radius":2}}`, nil},
+		{`{"shape":{"kind":"rect","width":3}}`, nil},
+		{`{"shape":{"kind":"circle","width":3}}`, &ValidateError{ParamName: "shape.radius", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`{"shape":{"kind":"circle","radius":200}}`, &ValidateError{ParamName: "shape.radius", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"shape":{"kind":"star"}}`, &ValidateError{ParamName: "shape.kind", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"shape":{"radius":2}}`, &ValidateError{ParamName: "shape.kind", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out drawing
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	var out drawing
+	checkError(t, Validate(decodeInput(t, `{"shape":{"kind":"rect","width":3}}`), &out), nil)
+	if rect, ok := out.Shape.(testRect); !ok || rect.Width != 3 {
+		t.Fatalf("expected a decoded testRect, got %#v", out.Shape)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var emailProviders = struct {
+	sync.RWMutex
+	m map[string]func(local string) string
+}{
+	m: map[string]func(local string) string{
+		"gmail.com":      canonGmail,
+		"googlemail.com": canonGmail,
+	},
+}
+
+// RegisterEmailProvider sets how emailcanon rewrites the already lowercased
+// local part of the addresses of domain. It panics if the domain is already
+// registered.
+func RegisterEmailProvider(domain string, canon func(local string) string) {
+	if domain == "" || canon == nil {
+		panic("Email provider must have a domain and a canonicalization")
+	}
+
+	domain = strings.ToLower(domain)
+
+	emailProviders.Lock()
+	defer emailProviders.Unlock()
+
+	if _, ok := emailProviders.m[domain]; ok {
+		panic(fmt.Sprintf("Email provider '%s' already registered", domain))
+	}
+
+	emailProviders.m[domain] = canon
+}
+
+// canonGmail drops the dots and the +tag, Gmail ignores both.
+func canonGmail(local string) string {
+	if i := strings.IndexByte(local, '+'); i >= 0 {
+		local = local[:i]
+	}
+
+	return strings.ReplaceAll(local, ".", "")
+}
+
+// isPlainAddress accepts a bare addr-spec, without a display name or angle
+// brackets.
+func isPlainAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// checkEmail accepts what net/mail.ParseAddress parses as an RFC 5322 address,
+// e.g. quoted local parts, but neither a display name nor angle brackets.
+// The domain is not resolved.
+func checkEmail(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_EMAIL, structField, fValue)
+
+	s := fValue.String()
+	if addr, err := mail.ParseAddress(s); err == nil && addr.Name == "" && !strings.ContainsAny(s, "<>") {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid email address", vParams.Name),
+	}
+}
+
+// checkEmailCanon validates the address and stores its canonical form in the
+// field: lowercased and rewritten by the provider of its domain, if any.
+func checkEmailCanon(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_EMAIL_CANON, structField, fValue)
+
+	addr := fValue.String()
+	if !isPlainAddress(addr) {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a valid email address", vParams.Name),
+		}
+	}
+
+	at := strings.LastIndexByte(addr, '@')
+	local, domain := strings.ToLower(addr[:at]), strings.ToLower(addr[at+1:])
+
+	emailProviders.RLock()
+	canon, ok := emailProviders.m[domain]
+	emailProviders.RUnlock()
+	if ok {
+		local = canon(local)
+	}
+
+	fValue.SetString(local + "@" + domain)
+	return nil
+}
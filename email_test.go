@@ -0,0 +1,60 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestEmailCanon(t *testing.T) {
+	type signup struct {
+		Email string `validate:"name=email,emailcanon"`
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"email":"First.Last+promo@gmail.com"}`, "firstlast@gmail.com"},
+		{`{"email":"First.Last+promo@GoogleMail.com"}`, "firstlast@googlemail.com"},
+		{`{"email":"First.Last+promo@Example.COM"}`, "first.last+promo@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out signup
+			checkError(t, Validate(decodeInput(t, tt.input), &out), nil)
+			if out.Email != tt.want {
+				t.Fatalf("email = %q, want %q", out.Email, tt.want)
+			}
+		})
+	}
+
+	var out signup
+	checkError(t, Validate(decodeInput(t, `{"email":"Neo <neo@example.com>"}`), &out),
+		&ValidateError{ParamName: "email", Code: VALIDATE_ERR_CODE_INVALID})
+}
+
+func TestEmail(t *testing.T) {
+	type contact struct {
+		Email string `validate:"name=email,email"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"email":"neo@example.com"}`, nil},
+		{`{"email":"\"neo anderson\"@example.com"}`, nil},
+		{`{}`, nil},
+		{`{"email":"neo.example.com"}`, &ValidateError{ParamName: "email", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"email":"Neo <neo@example.com>"}`, &ValidateError{ParamName: "email", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out contact
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// shannonBits estimates the entropy of s in bits: the Shannon entropy of its
+// rune distribution times its length in runes.
+func shannonBits(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	perRune := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		perRune -= p * math.Log2(p)
+	}
+
+	return perRune * float64(total)
+}
+
+func checkMinEntropy(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	mustBeString(TAG_FIELD_MIN_ENTROPY, structField, fValue)
+
+	minBits, err := strconv.ParseFloat(tagRawVal, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse '%s' tag as a float", TAG_FIELD_MIN_ENTROPY))
+	}
+
+	if shannonBits(fValue.String()) < minBits {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is too predictable (< %g bits of entropy)", vParams.Name, minBits),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestMinEntropy(t *testing.T) {
+	type credentials struct {
+		Password string `validate:"name=password,minentropy=40"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"password":"T7#kq!Zp9$Lm"}`, nil},
+		{`{"password":"password"}`, &ValidateError{ParamName: "password", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"password":"aaaaaaaaaaaaaaaaaaaa"}`, &ValidateError{ParamName: "password", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out credentials
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// equalValues compares a decoded field with a value supplied by the caller.
+// Numbers are compared by value whatever their Go types are.
+func equalValues(fValue reflect.Value, expected interface{}) bool {
+	ev := reflect.ValueOf(expected)
+	if !ev.IsValid() {
+		return fValue.IsZero()
+	}
+
+	if f, ok := numericAsFloat(fValue); ok {
+		e, ok := numericAsFloat(ev)
+		return ok && f == e
+	}
+
+	if !ev.Type().ConvertibleTo(fValue.Type()) {
+		return false
+	}
+
+	return reflect.DeepEqual(ev.Convert(fValue.Type()).Interface(), fValue.Interface())
+}
+
+func checkEqExpected(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, name string, o *options) *ValidateError {
+	expected, ok := o.expected[name]
+	if !ok {
+		return nil
+	}
+
+	if equalValues(fValue, expected) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' does not match the expected %s (%v)", vParams.Name, name, expected),
+	}
+}
+
+// exceeds reports whether the numeric fValue is greater than previous. Values
+// of integer kinds are compared exactly, the others as floats.
+func exceeds(fValue, previous reflect.Value) (bool, bool) {
+	switch {
+	case isIntKind(fValue.Kind()) && isIntKind(previous.Kind()):
+		return fValue.Int() > previous.Int(), true
+	case isUintKind(fValue.Kind()) && isUintKind(previous.Kind()):
+		return fValue.Uint() > previous.Uint(), true
+	}
+
+	f, ok := numericAsFloat(fValue)
+	if !ok {
+		return false, false
+	}
+
+	p, ok := numericAsFloat(previous)
+	if !ok {
+		return false, false
+	}
+
+	return f > p, true
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func checkIncreasing(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, name string, o *options) *ValidateError {
+	previous, ok := o.expected[name]
+	if !ok {
+		return nil
+	}
+
+	greater, ok := exceeds(fValue, reflect.ValueOf(previous))
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field or the previous %s is not a number", TAG_FIELD_INCREASING, structField.Name, name))
+	}
+
+	if !greater {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_SMALL,
+			OriginalError: fmt.Errorf("Param '%s' must be greater than the previous %s (%v)", vParams.Name, name, previous),
+		}
+	}
+
+	return nil
+}
+
+func checkExists(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, resourceType string, o *options) *ValidateError {
+	if o.resolver == nil {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' requires a resolver, see WithResolver", TAG_FIELD_EXISTS, structField.Name))
+	}
+
+	ok, err := o.resolver(o.ctx, resourceType, fValue.Interface())
+	if err != nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_UNKNOWN,
+			OriginalError: err,
+		}
+	}
+
+	if !ok {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' refers to a missing %s", vParams.Name, resourceType),
+		}
+	}
+
+	return nil
+}
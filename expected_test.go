@@ -0,0 +1,95 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEqExpected(t *testing.T) {
+	type update struct {
+		Version int    `validate:"name=version,eqexpected=version"`
+		Owner   string `validate:"name=owner,eqexpected=owner"`
+	}
+
+	expected := map[string]interface{}{"version": int64(3), "owner": "alice"}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"version":3,"owner":"alice"}`, nil},
+		{`{"version":2}`, &ValidateError{ParamName: "version", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"owner":"bob"}`, &ValidateError{ParamName: "owner", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out update
+			checkError(t, Validate(decodeInput(t, tt.input), &out, WithExpected(expected)), tt.want)
+		})
+	}
+
+	var out update
+	checkError(t, Validate(decodeInput(t, `{"version":2}`), &out), nil)
+}
+
+func TestExists(t *testing.T) {
+	type transfer struct {
+		Account string `validate:"name=account,exists=account"`
+	}
+
+	resolver := func(ctx context.Context, resourceType string, value interface{}) (bool, error) {
+		if value == "broken" {
+			return false, errors.New("database is down")
+		}
+
+		return resourceType == "account" && value == "acc-1", nil
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"account":"acc-1"}`, nil},
+		{`{"account":"acc-2"}`, &ValidateError{ParamName: "account", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"account":"broken"}`, &ValidateError{ParamName: "account", Code: VALIDATE_ERR_CODE_UNKNOWN}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out transfer
+			checkError(t, Validate(decodeInput(t, tt.input), &out, WithResolver(resolver)), tt.want)
+		})
+	}
+}
+
+func TestIncreasing(t *testing.T) {
+	type meter struct {
+		Reading uint64  `validate:"name=reading,increasing=reading"`
+		Price   float64 `validate:"name=price,increasing=price"`
+	}
+
+	previous := map[string]interface{}{"reading": uint64(1200), "price": 9.5}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"reading":1201,"price":9.75}`, nil},
+		{`{"reading":1200}`, &ValidateError{ParamName: "reading", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"reading":1100}`, &ValidateError{ParamName: "reading", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"price":9.5}`, &ValidateError{ParamName: "price", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out meter
+			checkError(t, Validate(decodeInput(t, tt.input), &out, WithExpected(previous)), tt.want)
+		})
+	}
+}
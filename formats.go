@@ -0,0 +1,304 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	FORMAT_JWT          = "jwt"
+	FORMAT_INT8         = "int8"
+	FORMAT_INT16        = "int16"
+	FORMAT_INT32        = "int32"
+	FORMAT_INT64        = "int64"
+	FORMAT_UINT8        = "uint8"
+	FORMAT_UINT16       = "uint16"
+	FORMAT_UINT32       = "uint32"
+	FORMAT_UINT64       = "uint64"
+	FORMAT_RELPATH      = "relpath"
+	FORMAT_ABSPATH      = "abspath"
+	FORMAT_PORT         = "port"
+	FORMAT_PRIVPORT     = "privport"
+	FORMAT_USERPORT     = "userport"
+	FORMAT_URL_ENCODED  = "urlencoded"
+	FORMAT_IMAGE        = "image"
+	FORMAT_CRON         = "cron"
+	FORMAT_ISO_DURATION = "iso8601duration"
+	FORMAT_BCP47        = "bcp47"
+	FORMAT_JSON_PATCH   = "jsonpatch"
+	FORMAT_SLUG         = "slug"
+
+	FORMAT_REACHABLE = "reachable"
+)
+
+type formatChecker func(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError
+
+var formatCheckers = map[string]formatChecker{
+	FORMAT_JWT:          checkJWT,
+	FORMAT_INT8:         intRangeChecker(FORMAT_INT8, math.MinInt8, math.MaxInt8),
+	FORMAT_INT16:        intRangeChecker(FORMAT_INT16, math.MinInt16, math.MaxInt16),
+	FORMAT_INT32:        intRangeChecker(FORMAT_INT32, math.MinInt32, math.MaxInt32),
+	FORMAT_INT64:        intRangeChecker(FORMAT_INT64, math.MinInt64, math.MaxInt64),
+	FORMAT_UINT8:        intRangeChecker(FORMAT_UINT8, 0, math.MaxUint8),
+	FORMAT_UINT16:       intRangeChecker(FORMAT_UINT16, 0, math.MaxUint16),
+	FORMAT_UINT32:       intRangeChecker(FORMAT_UINT32, 0, math.MaxUint32),
+	FORMAT_UINT64:       intRangeChecker(FORMAT_UINT64, 0, math.MaxUint64),
+	FORMAT_RELPATH:      checkRelPath,
+	FORMAT_ABSPATH:      checkAbsPath,
+	FORMAT_PORT:         intRangeChecker(FORMAT_PORT, 1, 65535),
+	FORMAT_PRIVPORT:     intRangeChecker(FORMAT_PRIVPORT, 1, 1023),
+	FORMAT_USERPORT:     intRangeChecker(FORMAT_USERPORT, 1024, 49151),
+	FORMAT_URL_ENCODED:  checkURLEncoded,
+	FORMAT_IMAGE:        checkImage,
+	FORMAT_CRON:         checkCron,
+	FORMAT_ISO_DURATION: checkISODuration,
+	FORMAT_BCP47:        checkBCP47,
+	FORMAT_JSON_PATCH:   checkJSONPatch,
+	FORMAT_SLUG:         checkSlug,
+}
+
+func checkFormat(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, format string, o *options) *ValidateError {
+	if format == FORMAT_REACHABLE {
+		if !o.networkChecks {
+			return nil
+		}
+
+		return checkReachable(o.ctx, vParams, structField, fValue)
+	}
+
+	checker, ok := formatCheckers[format]
+	if !ok {
+		panic(fmt.Sprintf("Unknown format '%s' on field '%s'", format, structField.Name))
+	}
+
+	return checker(vParams, structField, fValue)
+}
+
+var jwtRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`)
+
+// checkJWT verifies only the structure of a token, the signature is not checked.
+func checkJWT(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	token := fValue.String()
+	if jwtRegexp.MatchString(token) {
+		segments := strings.Split(token, ".")
+		_, errHeader := base64.RawURLEncoding.DecodeString(segments[0])
+		_, errPayload := base64.RawURLEncoding.DecodeString(segments[1])
+		if errHeader == nil && errPayload == nil {
+			return nil
+		}
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid JWT", vParams.Name),
+	}
+}
+
+// intRangeChecker bounds a numeric field to an inclusive integer range, such
+// as the range of a DB column type, whatever the Go type of the field is.
+func intRangeChecker(format string, min int64, max uint64) formatChecker {
+	return func(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+		tooSmall, tooBig := false, false
+		switch fValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v := fValue.Int()
+			tooSmall = v < min
+			tooBig = v > 0 && uint64(v) > max
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			v := fValue.Uint()
+			tooSmall = min > 0 && v < uint64(min)
+			tooBig = v > max
+		case reflect.Float32, reflect.Float64:
+			v := fValue.Float()
+			tooSmall = v < float64(min)
+			tooBig = v > float64(max)
+		default:
+			panic(fmt.Sprintf("Format '%s' cannot be applied to field '%s'. "+
+				"The field is not an integer or float", format, structField.Name))
+		}
+
+		if tooSmall {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_SMALL,
+				OriginalError: fmt.Errorf("Param '%s' is too small for %s (< %d)", vParams.Name, format, min),
+			}
+		}
+
+		if tooBig {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_BIG,
+				OriginalError: fmt.Errorf("Param '%s' is too big for %s (> %d)", vParams.Name, format, max),
+			}
+		}
+
+		return nil
+	}
+}
+
+// checkRelPath accepts a slash-separated relative path which does not
+// escape its root once cleaned.
+func checkRelPath(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	p := fValue.String()
+	if !path.IsAbs(p) {
+		cleaned := path.Clean(p)
+		if cleaned != ".." && !strings.HasPrefix(cleaned, "../") {
+			return nil
+		}
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid relative path", vParams.Name),
+	}
+}
+
+var isoDurationRegexp = regexp.MustCompile(`^P(?:\d+(?:[.,]\d+)?Y)?(?:\d+(?:[.,]\d+)?M)?(?:\d+(?:[.,]\d+)?W)?(?:\d+(?:[.,]\d+)?D)?(?:T(?:\d+(?:[.,]\d+)?H)?(?:\d+(?:[.,]\d+)?M)?(?:\d+(?:[.,]\d+)?S)?)?$`)
+
+// checkISODuration accepts ISO 8601 durations such as P1Y2M10DT2H30M. At
+// least one component is required, and so is one after the time designator.
+func checkISODuration(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	d := fValue.String()
+	if d != "P" && !strings.HasSuffix(d, "T") && isoDurationRegexp.MatchString(d) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid ISO 8601 duration", vParams.Name),
+	}
+}
+
+var bcp47Regexp = regexp.MustCompile(`(?i)^(?:[a-z]{2,3}(?:-[a-z]{3}){0,3}|[a-z]{4,8})` +
+	`(?:-[a-z]{4})?(?:-(?:[a-z]{2}|[0-9]{3}))?(?:-(?:[a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` +
+	`(?:-[0-9a-wy-z](?:-[a-z0-9]{2,8})+)*(?:-x(?:-[a-z0-9]{1,8})+)?$`)
+
+// checkBCP47 validates the structure of a language tag such as zh-Hant-TW:
+// language, script, region, variants and extensions. The subtags are not
+// looked up in the IANA registry.
+func checkBCP47(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	if bcp47Regexp.MatchString(fValue.String()) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid BCP 47 language tag", vParams.Name),
+	}
+}
+
+func checkAbsPath(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	if path.IsAbs(fValue.String()) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid absolute path", vParams.Name),
+	}
+}
+
+func checkURLEncoded(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	if _, err := url.QueryUnescape(fValue.String()); err != nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not properly percent-encoded", vParams.Name),
+		}
+	}
+
+	return nil
+}
+
+// checkURL accepts an absolute URL with a scheme and a host.
+func checkURL(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_URL, structField, fValue)
+
+	if u, err := url.ParseRequestURI(fValue.String()); err == nil && u.Scheme != "" && u.Host != "" {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid absolute URL", vParams.Name),
+	}
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// checkUUID accepts the canonical 8-4-4-4-12 hex form of any UUID version.
+func checkUUID(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_UUID, structField, fValue)
+
+	if uuidRegexp.MatchString(fValue.String()) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid UUID", vParams.Name),
+	}
+}
+
+// checkReachable issues a HEAD request to the URL held by the field and
+// expects a 2xx response.
+func checkReachable(ctx context.Context, vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	valErr := &ValidateError{
+		ParamName: vParams.Name,
+		Code:      VALIDATE_ERR_CODE_INVALID,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fValue.String(), nil)
+	if err != nil {
+		valErr.OriginalError = fmt.Errorf("Param '%s' is not a valid URL: %s", vParams.Name, err.Error())
+		return valErr
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		valErr.OriginalError = fmt.Errorf("Param '%s' is not reachable: %s", vParams.Name, err.Error())
+		return valErr
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		valErr.OriginalError = fmt.Errorf("Param '%s' is not reachable: %s", vParams.Name, resp.Status)
+		return valErr
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"net/http"
+	"sync"
+)
+
+var httpStatuses = struct {
+	sync.RWMutex
+	m map[ErrorCode]int
+}{
+	m: map[ErrorCode]int{
+		VALIDATE_ERR_CODE_UNKNOWN:           http.StatusInternalServerError,
+		VALIDATE_ERR_CODE_MISSING_REQ_PARAM: http.StatusBadRequest,
+		VALIDATE_ERR_CODE_UNPARSABLE:        http.StatusBadRequest,
+		VALIDATE_ERR_CODE_TOO_LONG:          http.StatusUnprocessableEntity,
+		VALIDATE_ERR_CODE_TOO_SHORT:         http.StatusUnprocessableEntity,
+		VALIDATE_ERR_CODE_TOO_BIG:           http.StatusUnprocessableEntity,
+		VALIDATE_ERR_CODE_TOO_SMALL:         http.StatusUnprocessableEntity,
+		VALIDATE_ERR_CODE_INVALID:           http.StatusUnprocessableEntity,
+		VALIDATE_ERR_CODE_NOT_ALLOWED:       http.StatusUnprocessableEntity,
+	},
+}
+
+// RegisterHTTPStatus overrides the HTTP status HTTPStatus returns for the
+// error code. Unlike the other registrations, it replaces the current one.
+func RegisterHTTPStatus(code ErrorCode, status int) {
+	httpStatuses.Lock()
+	defer httpStatuses.Unlock()
+
+	httpStatuses.m[code] = status
+}
+
+// HTTPStatus maps a validation error to the status of the response: 200 for
+// nil, the status of the code of a *ValidateError or of the first error of a
+// *MultiError, 500 otherwise.
+func HTTPStatus(err error) int {
+	var valErr *ValidateError
+	switch e := err.(type) {
+	case nil:
+		return http.StatusOK
+	case *ValidateError:
+		valErr = e
+	case *MultiError:
+		if len(e.Errors) == 0 {
+			return http.StatusOK
+		}
+
+		valErr = e.Errors[0]
+	default:
+		return http.StatusInternalServerError
+	}
+
+	httpStatuses.RLock()
+	defer httpStatuses.RUnlock()
+
+	if status, ok := httpStatuses.m[valErr.Code]; ok {
+		return status
+	}
+
+	return http.StatusBadRequest
+}
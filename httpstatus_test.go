@@ -0,0 +1,56 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPStatusDefaults(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		want int
+	}{
+		{VALIDATE_ERR_CODE_UNKNOWN, http.StatusInternalServerError},
+		{VALIDATE_ERR_CODE_MISSING_REQ_PARAM, http.StatusBadRequest},
+		{VALIDATE_ERR_CODE_UNPARSABLE, http.StatusBadRequest},
+		{VALIDATE_ERR_CODE_TOO_LONG, http.StatusUnprocessableEntity},
+		{VALIDATE_ERR_CODE_TOO_SHORT, http.StatusUnprocessableEntity},
+		{VALIDATE_ERR_CODE_TOO_BIG, http.StatusUnprocessableEntity},
+		{VALIDATE_ERR_CODE_TOO_SMALL, http.StatusUnprocessableEntity},
+		{VALIDATE_ERR_CODE_INVALID, http.StatusUnprocessableEntity},
+		{VALIDATE_ERR_CODE_NOT_ALLOWED, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		if got := HTTPStatus(&ValidateError{Code: tt.code}); got != tt.want {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+
+	if got := HTTPStatus(nil); got != http.StatusOK {
+		t.Errorf("HTTPStatus(nil) = %d, want %d", got, http.StatusOK)
+	}
+
+	if got := HTTPStatus(errors.New("boom")); got != http.StatusInternalServerError {
+		t.Errorf("HTTPStatus(error) = %d, want %d", got, http.StatusInternalServerError)
+	}
+
+	multiErr := &MultiError{Errors: []*ValidateError{{Code: VALIDATE_ERR_CODE_UNPARSABLE}, {Code: VALIDATE_ERR_CODE_TOO_BIG}}}
+	if got := HTTPStatus(multiErr); got != http.StatusBadRequest {
+		t.Errorf("HTTPStatus(MultiError) = %d, want %d", got, http.StatusBadRequest)
+	}
+}
+
+func TestRegisterHTTPStatus(t *testing.T) {
+	defer RegisterHTTPStatus(VALIDATE_ERR_CODE_NOT_ALLOWED, http.StatusUnprocessableEntity)
+
+	RegisterHTTPStatus(VALIDATE_ERR_CODE_NOT_ALLOWED, http.StatusForbidden)
+	if got := HTTPStatus(&ValidateError{Code: VALIDATE_ERR_CODE_NOT_ALLOWED}); got != http.StatusForbidden {
+		t.Fatalf("HTTPStatus = %d, want the overridden %d", got, http.StatusForbidden)
+	}
+}
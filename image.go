@@ -0,0 +1,63 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"reflect"
+	"strings"
+)
+
+// IMAGE_MAX_ENCODED_SIZE bounds the length of the base64 strings checked by
+// format=image, larger values are rejected without being decoded.
+const IMAGE_MAX_ENCODED_SIZE = 8 << 20
+
+var imageFormats = map[string]bool{
+	"png":  true,
+	"jpeg": true,
+}
+
+// checkImage accepts a base64 string, optionally given as a data URI, which
+// holds a PNG or JPEG image. Only the image header is decoded.
+func checkImage(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	valErr := &ValidateError{
+		ParamName: vParams.Name,
+		Code:      VALIDATE_ERR_CODE_INVALID,
+	}
+
+	encoded := fValue.String()
+	if strings.HasPrefix(encoded, "data:") {
+		if i := strings.Index(encoded, ";base64,"); i >= 0 {
+			encoded = encoded[i+len(";base64,"):]
+		}
+	}
+
+	if len(encoded) > IMAGE_MAX_ENCODED_SIZE {
+		valErr.Code = VALIDATE_ERR_CODE_TOO_LONG
+		valErr.OriginalError = fmt.Errorf("Param '%s' is too long (> %d)", vParams.Name, IMAGE_MAX_ENCODED_SIZE)
+		return valErr
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		valErr.OriginalError = fmt.Errorf("Param '%s' is not valid base64", vParams.Name)
+		return valErr
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !imageFormats[format] {
+		valErr.OriginalError = fmt.Errorf("Param '%s' is not a PNG or JPEG image", vParams.Name)
+		return valErr
+	}
+
+	return nil
+}
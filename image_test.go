@@ -0,0 +1,49 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func TestFormatImage(t *testing.T) {
+	type avatar struct {
+		Image string `validate:"name=image,format=image"`
+	}
+
+	var pngData, gifData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&gifData, image.NewGray(image.Rect(0, 0, 1, 1)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	encodedPNG := base64.StdEncoding.EncodeToString(pngData.Bytes())
+
+	tests := []struct {
+		name  string
+		image string
+		want  *ValidateError
+	}{
+		{"png", encodedPNG, nil},
+		{"data uri", "data:image/png;base64," + encodedPNG, nil},
+		{"gif", base64.StdEncoding.EncodeToString(gifData.Bytes()), &ValidateError{ParamName: "image", Code: VALIDATE_ERR_CODE_INVALID}},
+		{"blob", base64.StdEncoding.EncodeToString([]byte("not an image")), &ValidateError{ParamName: "image", Code: VALIDATE_ERR_CODE_INVALID}},
+		{"not base64", "***", &ValidateError{ParamName: "image", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := avatar{Image: tt.image}
+			checkError(t, ValidateStruct(&out), tt.want)
+		})
+	}
+}
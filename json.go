@@ -0,0 +1,23 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "encoding/json"
+
+// ValidateJSON decodes a JSON object and validates its params into
+// outputStruct like Validate does.
+func ValidateJSON(data []byte, outputStruct interface{}, opts ...Option) error {
+	checkOutputStruct(outputStruct)
+
+	var inputData map[string]*json.RawMessage
+	if err := json.Unmarshal(data, &inputData); err != nil {
+		return &ValidateError{
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	return Validate(inputData, outputStruct, opts...)
+}
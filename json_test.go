@@ -0,0 +1,31 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestValidateJSON(t *testing.T) {
+	type item struct {
+		Title string `validate:"name=title,required,maxLen=5"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"title":"book"}`, nil},
+		{`{"title":"notebook"}`, &ValidateError{ParamName: "title", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{}`, &ValidateError{ParamName: "title", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`{"title":`, &ValidateError{Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{`["book"]`, &ValidateError{Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out item
+			checkError(t, ValidateJSON([]byte(tt.input), &out), tt.want)
+		})
+	}
+}
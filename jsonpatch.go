@@ -0,0 +1,97 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+var jsonPointerRegexp = regexp.MustCompile(`^(?:/(?:[^~/]|~[01])*)*$`)
+
+// jsonPatchOps lists the RFC 6902 operations along with the member each of
+// them requires besides op and path.
+var jsonPatchOps = map[string]string{
+	"add":     "value",
+	"remove":  "",
+	"replace": "value",
+	"move":    "from",
+	"copy":    "from",
+	"test":    "value",
+}
+
+// jsonPatchOperations returns the raw operations held by a []json.RawMessage
+// field, or by a json.RawMessage or string field holding the whole array.
+func jsonPatchOperations(structField reflect.StructField, fValue reflect.Value) ([]json.RawMessage, error) {
+	switch {
+	case fValue.Kind() == reflect.Slice && fValue.Type().Elem() == reflect.TypeOf(json.RawMessage{}):
+		return fValue.Interface().([]json.RawMessage), nil
+	case fValue.Kind() == reflect.String:
+		var ops []json.RawMessage
+		err := json.Unmarshal([]byte(fValue.String()), &ops)
+		return ops, err
+	case fValue.Kind() == reflect.Slice && fValue.Type().Elem().Kind() == reflect.Uint8:
+		var ops []json.RawMessage
+		err := json.Unmarshal(fValue.Bytes(), &ops)
+		return ops, err
+	}
+
+	panic(fmt.Sprintf("Format '%s' cannot be applied to field '%s'. "+
+		"The field is not a []json.RawMessage, json.RawMessage or string", FORMAT_JSON_PATCH, structField.Name))
+}
+
+func jsonPatchOpValid(raw json.RawMessage) bool {
+	var op map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &op); err != nil || op == nil {
+		return false
+	}
+
+	var name, path string
+	if json.Unmarshal(op["op"], &name) != nil || json.Unmarshal(op["path"], &path) != nil {
+		return false
+	}
+
+	required, ok := jsonPatchOps[name]
+	if !ok || !jsonPointerRegexp.MatchString(path) {
+		return false
+	}
+
+	switch required {
+	case "value":
+		_, ok = op["value"]
+		return ok
+	case "from":
+		var from string
+		return json.Unmarshal(op["from"], &from) == nil && jsonPointerRegexp.MatchString(from)
+	}
+
+	return true
+}
+
+func checkJSONPatch(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	ops, err := jsonPatchOperations(structField, fValue)
+	if err != nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a JSON patch array", vParams.Name),
+		}
+	}
+
+	for i, op := range ops {
+		if !jsonPatchOpValid(op) {
+			name := fmt.Sprintf("%s[%d]", vParams.Name, i)
+			return &ValidateError{
+				ParamName:     name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' is not a valid JSON patch operation", name),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFormatJSONPatch(t *testing.T) {
+	type update struct {
+		Patch []json.RawMessage `validate:"name=patch,format=jsonpatch"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"patch":[{"op":"add","path":"/a~1b","value":1},{"op":"remove","path":"/c"},{"op":"move","from":"/d","path":"/e"}]}`, nil},
+		{`{"patch":[{"op":"replace","path":"","value":null}]}`, nil},
+		{`{"patch":[{"op":"add","path":"/a","value":1},{"op":"merge","path":"/b"}]}`, &ValidateError{ParamName: "patch[1]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"patch":[{"op":"add","path":"/a"}]}`, &ValidateError{ParamName: "patch[0]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"patch":[{"op":"copy","path":"/a","from":"b"}]}`, &ValidateError{ParamName: "patch[0]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"patch":[{"op":"remove","path":"a~2"}]}`, &ValidateError{ParamName: "patch[0]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"patch":[1]}`, &ValidateError{ParamName: "patch[0]", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out update
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	type rawUpdate struct {
+		Patch string `validate:"name=patch,format=jsonpatch"`
+	}
+
+	raw := rawUpdate{Patch: `{"op":"add"}`}
+	checkError(t, ValidateStruct(&raw), &ValidateError{ParamName: "patch", Code: VALIDATE_ERR_CODE_INVALID})
+}
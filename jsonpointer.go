@@ -0,0 +1,68 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RequirePaths checks that every JSON pointer (RFC 6901) of paths resolves
+// to a non-null value in data, the error names the first missing one.
+func RequirePaths(data []byte, paths ...string) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return &ValidateError{
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	for _, path := range paths {
+		if !jsonPointerRegexp.MatchString(path) {
+			panic(fmt.Sprintf("Path '%s' is not a valid JSON pointer", path))
+		}
+
+		if resolvePointer(doc, path) == nil {
+			return &ValidateError{
+				ParamName:     path,
+				Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+				OriginalError: fmt.Errorf("Path '%s' is required", path),
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolvePointer returns the value the pointer refers to, or nil if there is
+// none or it is null.
+func resolvePointer(doc interface{}, path string) interface{} {
+	if path == "" {
+		return doc
+	}
+
+	for _, token := range strings.Split(path[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			doc = v[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) || token != strconv.Itoa(i) {
+				return nil
+			}
+
+			doc = v[i]
+		default:
+			return nil
+		}
+	}
+
+	return doc
+}
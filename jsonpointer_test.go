@@ -0,0 +1,32 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestRequirePaths(t *testing.T) {
+	data := []byte(`{"user":{"address":{"zip":"01001"},"tags":["a","b"],"a/b":1,"note":null}}`)
+
+	tests := []struct {
+		path string
+		want *ValidateError
+	}{
+		{"/user/address/zip", nil},
+		{"/user/tags/1", nil},
+		{"/user/a~1b", nil},
+		{"/user/address/city", &ValidateError{ParamName: "/user/address/city", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{"/user/tags/2", &ValidateError{ParamName: "/user/tags/2", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{"/user/tags/01", &ValidateError{ParamName: "/user/tags/01", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{"/user/note", &ValidateError{ParamName: "/user/note", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			checkError(t, RequirePaths(data, tt.path), tt.want)
+		})
+	}
+
+	checkError(t, RequirePaths([]byte(`{`), "/a"), &ValidateError{Code: VALIDATE_ERR_CODE_UNPARSABLE})
+}
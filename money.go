@@ -0,0 +1,95 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// currencyMinorUnits holds the ISO 4217 number of decimal places of the
+// commonly used currencies.
+var currencyMinorUnits = map[string]int{
+	"AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "MXN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PLN": 2,
+	"RUB": 2, "SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "UAH": 2,
+	"UGX": 0, "USD": 2, "VND": 0, "XAF": 0, "XOF": 0, "ZAR": 2,
+}
+
+func fractionDigits(v reflect.Value) (int, bool) {
+	var s string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0, true
+	case reflect.Float32:
+		s = strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		s = strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.String:
+		m := decimalRegexp.FindStringSubmatch(v.String())
+		if m == nil {
+			return 0, false
+		}
+
+		return len(m[3]), true
+	default:
+		return 0, false
+	}
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1, true
+	}
+
+	return 0, true
+}
+
+// checkMoneyScale expects the amount to have no more fractional digits than
+// the currency held by the referenced param allows.
+func checkMoneyScale(pf presentField, present []presentField, currencyParam string) *ValidateError {
+	switch pf.value.Kind() {
+	case reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a number or a string", TAG_FIELD_MONEY_SCALE, pf.field.Name))
+	}
+
+	currencyValue, ok := findPresent(present, currencyParam)
+	if !ok {
+		return nil
+	}
+
+	if currencyValue.Kind() != reflect.String {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' refers to '%s' which is not a string",
+			TAG_FIELD_MONEY_SCALE, pf.field.Name, currencyParam))
+	}
+
+	currency := strings.ToUpper(currencyValue.String())
+	scale, ok := currencyMinorUnits[currency]
+	if !ok {
+		return &ValidateError{
+			ParamName:     currencyParam,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is not a supported currency", currencyParam),
+		}
+	}
+
+	digits, ok := fractionDigits(pf.value)
+	if !ok || digits > scale {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' has more than %d decimal places allowed for %s", pf.params.Name, scale, currency),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestMoneyScale(t *testing.T) {
+	type price struct {
+		Currency string  `validate:"name=currency"`
+		Amount   float64 `validate:"name=amount,moneyscale=currency"`
+		Total    string  `validate:"name=total,moneyscale=currency"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"currency":"USD","amount":19.99,"total":"39.98"}`, nil},
+		{`{"currency":"JPY","amount":1500,"total":"3000"}`, nil},
+		{`{"currency":"JPY","amount":1500.5}`, &ValidateError{ParamName: "amount", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"currency":"USD","total":"1.999"}`, &ValidateError{ParamName: "total", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"currency":"XYZ","amount":1}`, &ValidateError{ParamName: "currency", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out price
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// MultiError aggregates the errors of a validation, see ValidateMany.
+type MultiError struct {
+	Errors []*ValidateError
+}
+
+// Error joins the messages of the errors sorted by param name, the errors of
+// the same param keep their order.
+func (m *MultiError) Error() string {
+	errs := append([]*ValidateError(nil), m.Errors...)
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].ParamName < errs[j].ParamName
+	})
+
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// ByParam returns the errors reported for the param, name is the full
+// param name such as children[0].name.
+func (m *MultiError) ByParam(name string) []*ValidateError {
+	var errs []*ValidateError
+	for _, e := range m.Errors {
+		if e.ParamName == name {
+			errs = append(errs, e)
+		}
+	}
+
+	return errs
+}
+
+// ValidateMany is ValidateAll returning the errors as a single *MultiError,
+// or nil if the input is valid.
+func ValidateMany(inputData map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) error {
+	errs := ValidateAll(inputData, outputStruct, opts...)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &MultiError{Errors: errs}
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestValidateMany(t *testing.T) {
+	type signup struct {
+		Login string `validate:"name=login,required"`
+		Code  string `validate:"name=code,minLen=4,oneof=abcd efgh"`
+		Age   int    `validate:"name=age,min=18"`
+	}
+
+	var out signup
+	if err := ValidateMany(decodeInput(t, `{"login":"neo","code":"abcd","age":20}`), &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := ValidateMany(decodeInput(t, `{"code":"xy","age":5}`), &out)
+	multiErr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected a *MultiError, got %v", err)
+	}
+
+	if len(multiErr.ByParam("code")) != 2 || len(multiErr.ByParam("age")) != 1 || len(multiErr.ByParam("other")) != 0 {
+		t.Fatalf("unexpected errors by param: %v", multiErr.Errors)
+	}
+
+	want := "Param 'age' is too small (< 18); " +
+		"Param 'code' is too short (< 4); " +
+		"Param 'code' is not one of the allowed values (abcd, efgh); " +
+		"Param 'login' is required"
+	if multiErr.Error() != want {
+		t.Fatalf("unexpected message:\n%s\nwant:\n%s", multiErr.Error(), want)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// VALIDATE_MAX_DEPTH bounds the recursion into nested values, which keeps
+// self-referential types such as trees from nesting without limit.
+const VALIDATE_MAX_DEPTH = 32
+
+// hasValidateTags reports whether t or any struct reachable from its fields
+// carries validate tags.
+func hasValidateTags(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+		return false
+	}
+
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup(VALIDATE_TAG_NAME); ok {
+			return true
+		}
+
+		if hasValidateTags(t.Field(i).Type, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func structElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t, t.Kind() == reflect.Struct && t != timeType
+}
+
+// isNestedSlice reports whether t is a slice of structs (or of pointers to
+// structs) whose elements have to be validated one by one.
+func isNestedSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+
+	elem, ok := structElem(t.Elem())
+	return ok && hasValidateTags(elem, make(map[reflect.Type]bool))
+}
+
+// isNestedStruct reports whether t is a struct (or a pointer to one) whose
+// fields have to be validated. Types decoding themselves are left opaque.
+func isNestedStruct(t reflect.Type) bool {
+	elem, ok := structElem(t)
+	if !ok || reflect.PtrTo(elem).Implements(jsonUnmarshalerType) {
+		return false
+	}
+
+	return hasValidateTags(elem, make(map[reflect.Type]bool))
+}
+
+func tooDeep(paramName string) *ValidateError {
+	return &ValidateError{
+		ParamName:     paramName,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is nested deeper than %d levels", paramName, VALIDATE_MAX_DEPTH),
+	}
+}
+
+func decodeNestedSlice(raw json.RawMessage, fValue reflect.Value, paramName string, depth int, o *options) *ValidateError {
+	if depth >= VALIDATE_MAX_DEPTH {
+		return o.fail(tooDeep(paramName))
+	}
+
+	var elems []*json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		return o.fail(&ValidateError{
+			ParamName:     paramName,
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		})
+	}
+
+	if elems == nil {
+		fValue.Set(reflect.Zero(fValue.Type()))
+		return nil
+	}
+
+	fValue.Set(reflect.MakeSlice(fValue.Type(), len(elems), len(elems)))
+	for i, elemRaw := range elems {
+		if elemRaw == nil {
+			continue
+		}
+
+		elemName := fmt.Sprintf("%s[%d]", paramName, i)
+		if valErr := decodeNestedStruct(*elemRaw, fValue.Index(i), elemName, depth, o); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+// decodeNestedStruct decodes a JSON object into a struct or a pointer to
+// one and validates its fields, their param names are prefixed with
+// paramName.
+func decodeNestedStruct(raw json.RawMessage, fValue reflect.Value, paramName string, depth int, o *options) *ValidateError {
+	if depth >= VALIDATE_MAX_DEPTH {
+		return o.fail(tooDeep(paramName))
+	}
+
+	var data map[string]*json.RawMessage
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return o.fail(&ValidateError{
+			ParamName:     paramName,
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		})
+	}
+
+	if data == nil {
+		fValue.Set(reflect.Zero(fValue.Type()))
+		return nil
+	}
+
+	if fValue.Kind() == reflect.Ptr {
+		fValue.Set(reflect.New(fValue.Type().Elem()))
+		fValue = fValue.Elem()
+	}
+
+	return validateInput(data, fValue, paramName+".", depth+1, o)
+}
+
+func validatePopulatedSlice(fValue reflect.Value, paramName string, depth int, o *options) *ValidateError {
+	if depth >= VALIDATE_MAX_DEPTH {
+		return tooDeep(paramName)
+	}
+
+	for i := 0; i < fValue.Len(); i++ {
+		elem := fValue.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+
+			elem = elem.Elem()
+		}
+
+		if valErr := validatePopulated(elem, fmt.Sprintf("%s[%d].", paramName, i), depth+1, o); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+func validatePopulatedStruct(fValue reflect.Value, paramName string, depth int, o *options) *ValidateError {
+	if depth >= VALIDATE_MAX_DEPTH {
+		return tooDeep(paramName)
+	}
+
+	if fValue.Kind() == reflect.Ptr {
+		if fValue.IsNil() {
+			return nil
+		}
+
+		fValue = fValue.Elem()
+	}
+
+	return validatePopulated(fValue, paramName+".", depth+1, o)
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNestedStruct(t *testing.T) {
+	type address struct {
+		City string `validate:"name=city,required"`
+		Zip  string `validate:"name=zip,minLen=5,maxLen=5"`
+	}
+
+	type user struct {
+		Name     string    `validate:"name=name,required"`
+		Address  address   `validate:"name=address,required"`
+		Billing  *address  `validate:"name=billing"`
+		JoinedAt time.Time `validate:"name=joinedAt"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"name":"neo","address":{"city":"Kyiv","zip":"01001"},"joinedAt":"2024-01-09T10:00:00Z"}`, nil},
+		{`{"name":"neo","address":{"city":"Kyiv"},"billing":{"city":"Lviv","zip":"79000"}}`, nil},
+		{`{"name":"neo","address":{"city":"Kyiv","zip":"123"}}`, &ValidateError{ParamName: "address.zip", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{`{"name":"neo","address":{"zip":"01001"}}`, &ValidateError{ParamName: "address.city", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`{"name":"neo","address":{"city":"Kyiv"},"billing":{"zip":"79000"}}`, &ValidateError{ParamName: "billing.city", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`{"name":"neo"}`, &ValidateError{ParamName: "address", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`{"name":"neo","address":[]}`, &ValidateError{ParamName: "address", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out user
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+type treeNode struct {
+	Name     string     `validate:"name=name,required,maxLen=5"`
+	Children []treeNode `validate:"name=children"`
+}
+
+func TestRecursiveTree(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"name":"root","children":[{"name":"a","children":[{"name":"a1"}]}]}`, nil},
+		{`{"name":"root","children":[{"name":"a"},{"name":"b","children":[{"name":"toolong"}]}]}`,
+			&ValidateError{ParamName: "children[1].children[0].name", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"name":"root","children":[{"children":[]}]}`,
+			&ValidateError{ParamName: "children[0].name", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out treeNode
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestRecursiveTreeTooDeep(t *testing.T) {
+	input := `{"name":"n"}`
+	for i := 0; i <= VALIDATE_MAX_DEPTH; i++ {
+		input = `{"name":"n","children":[` + input + `]}`
+	}
+
+	var out treeNode
+	err := Validate(decodeInput(t, input), &out)
+	valErr, ok := err.(*ValidateError)
+	if !ok || valErr.Code != VALIDATE_ERR_CODE_INVALID || !strings.Contains(valErr.Error(), "deeper") {
+		t.Fatalf("expected a depth error, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build norm
+
+package validate
+
+import "golang.org/x/text/unicode/norm"
+
+func init() {
+	normalizers[TAG_FIELD_NFC] = norm.NFC.String
+	normalizers[TAG_FIELD_NFKC] = norm.NFKC.String
+}
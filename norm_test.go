@@ -0,0 +1,25 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build norm
+
+package validate
+
+import "testing"
+
+func TestNFC(t *testing.T) {
+	type person struct {
+		Name string `validate:"name=name,nfc,maxLen=4"`
+	}
+
+	// The decomposed "Jose\u0301" takes 5 runes and 4 once composed.
+	var out person
+	checkError(t, Validate(decodeInput(t, `{"name":"Jose\u0301"}`), &out), nil)
+	if out.Name != "Jos\u00e9" {
+		t.Fatalf("expected the composed form, got %q", out.Name)
+	}
+
+	checkError(t, Validate(decodeInput(t, `{"name":"Jose\u0301e"}`), &out),
+		&ValidateError{ParamName: "name", Code: VALIDATE_ERR_CODE_TOO_LONG})
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type baseline struct {
+	mean   float64
+	stddev float64
+}
+
+var baselines = struct {
+	sync.RWMutex
+	m map[string]baseline
+}{
+	m: make(map[string]baseline),
+}
+
+// RegisterBaseline sets the distribution referenced by zscore=name:bound.
+// Unlike the other registrations, a baseline may be registered again to
+// replace the previous one as the statistics get refreshed.
+func RegisterBaseline(name string, mean, stddev float64) {
+	if name == "" || stddev < 0 {
+		panic("Baseline must have a name and a non-negative standard deviation")
+	}
+
+	baselines.Lock()
+	defer baselines.Unlock()
+
+	baselines.m[name] = baseline{mean: mean, stddev: stddev}
+}
+
+func numericAsFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+func checkPow2(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	var v uint64
+	switch fValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fValue.Int() > 0 {
+			v = uint64(fValue.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v = fValue.Uint()
+	default:
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not an integer", TAG_FIELD_POW2, structField.Name))
+	}
+
+	if v > 0 && v&(v-1) == 0 {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a power of two", vParams.Name),
+	}
+}
+
+func checkIntegral(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	if fValue.Kind() != reflect.Float32 && fValue.Kind() != reflect.Float64 {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a float", TAG_FIELD_INTEGRAL, structField.Name))
+	}
+
+	v := fValue.Float()
+	if !math.IsInf(v, 0) && v == math.Trunc(v) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a whole number", vParams.Name),
+	}
+}
+
+// ROUNDED_TO_EPSILON is the relative error tolerated by roundedto, which
+// absorbs the float representation of decimal values such as 0.1.
+const ROUNDED_TO_EPSILON = 1e-9
+
+func checkRoundedTo(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	precision, err := strconv.ParseFloat(tagRawVal, 64)
+	if err != nil || precision <= 0 {
+		panic(fmt.Sprintf("Unable to parse '%s' tag as a positive float", TAG_FIELD_ROUNDED_TO))
+	}
+
+	v, ok := numericAsFloat(fValue)
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not an integer or float", TAG_FIELD_ROUNDED_TO, structField.Name))
+	}
+
+	rounded := math.Round(v/precision) * precision
+	if math.Abs(v-rounded) <= ROUNDED_TO_EPSILON*math.Max(1, math.Abs(v)) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not rounded to %v", vParams.Name, precision),
+	}
+}
+
+func checkZScore(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	parts := strings.SplitN(tagRawVal, ":", 2)
+	if len(parts) != 2 {
+		panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as baseline:bound", TAG_FIELD_ZSCORE, structField.Name))
+	}
+
+	bound, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || bound < 0 {
+		panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as baseline:bound", TAG_FIELD_ZSCORE, structField.Name))
+	}
+
+	v, ok := numericAsFloat(fValue)
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not an integer or float", TAG_FIELD_ZSCORE, structField.Name))
+	}
+
+	baselines.RLock()
+	b, ok := baselines.m[parts[0]]
+	baselines.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Unknown baseline '%s' on field '%s'", parts[0], structField.Name))
+	}
+
+	deviation := math.Abs(v - b.mean)
+	if deviation == 0 || (b.stddev > 0 && deviation/b.stddev <= bound) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is more than %v standard deviations from the mean", vParams.Name, bound),
+	}
+}
+
+// checkSoftMax never fails the validation, a value above the bound only
+// raises a warning.
+func checkSoftMax(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string, o *options) {
+	bound, err := strconv.ParseFloat(tagRawVal, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse '%s' tag as a float", TAG_FIELD_SOFT_MAX))
+	}
+
+	v, ok := numericAsFloat(fValue)
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not an integer or float", TAG_FIELD_SOFT_MAX, structField.Name))
+	}
+
+	if v > bound {
+		o.warn(&ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_BIG,
+			OriginalError: fmt.Errorf("Param '%s' is above the recommended maximum (> %v)", vParams.Name, bound),
+		})
+	}
+}
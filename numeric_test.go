@@ -0,0 +1,144 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestPow2(t *testing.T) {
+	type buffer struct {
+		Size  int  `validate:"name=size,pow2"`
+		Pages uint `validate:"name=pages,pow2"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"size":1024,"pages":1}`, nil},
+		{`{"size":1000}`, &ValidateError{ParamName: "size", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"size":0}`, &ValidateError{ParamName: "size", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"size":-1024}`, &ValidateError{ParamName: "size", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"pages":0}`, &ValidateError{ParamName: "pages", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out buffer
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestZScore(t *testing.T) {
+	RegisterBaseline("testlatency", 100, 10)
+
+	type sample struct {
+		Latency float64 `validate:"name=latency,zscore=testlatency:3"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"latency":100}`, nil},
+		{`{"latency":125}`, nil},
+		{`{"latency":70}`, nil},
+		{`{"latency":131}`, &ValidateError{ParamName: "latency", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"latency":-5}`, &ValidateError{ParamName: "latency", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out sample
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	RegisterBaseline("testlatency", 200, 10)
+	var out sample
+	checkError(t, Validate(decodeInput(t, `{"latency":200}`), &out), nil)
+}
+
+func TestSoftMax(t *testing.T) {
+	type upload struct {
+		SizeMB int `validate:"name=sizeMB,softmax=80,max=100"`
+	}
+
+	tests := []struct {
+		input    string
+		want     *ValidateError
+		warnings int
+	}{
+		{`{"sizeMB":50}`, nil, 0},
+		{`{"sizeMB":90}`, nil, 1},
+		{`{"sizeMB":120}`, &ValidateError{ParamName: "sizeMB", Code: VALIDATE_ERR_CODE_TOO_BIG}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var report Report
+			var out upload
+			checkError(t, Validate(decodeInput(t, tt.input), &out, WithReport(&report)), tt.want)
+
+			if len(report.Warnings) != tt.warnings {
+				t.Fatalf("expected %d warnings, got %v", tt.warnings, report.Warnings)
+			}
+
+			for _, w := range report.Warnings {
+				if w.ParamName != "sizeMB" || w.Code != VALIDATE_ERR_CODE_TOO_BIG {
+					t.Fatalf("unexpected warning: %s", w)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundedTo(t *testing.T) {
+	type quote struct {
+		Price float64 `validate:"name=price,roundedto=0.05"`
+		Lot   int     `validate:"name=lot,roundedto=100"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"price":10.15,"lot":300}`, nil},
+		{`{"price":0.3}`, nil},
+		{`{"price":10.13}`, &ValidateError{ParamName: "price", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"lot":250}`, &ValidateError{ParamName: "lot", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out quote
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestIntegral(t *testing.T) {
+	type order struct {
+		Quantity float64 `validate:"name=quantity,integral"`
+		Weight   float32 `validate:"name=weight,integral"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"quantity":3.0,"weight":2}`, nil},
+		{`{"quantity":-4}`, nil},
+		{`{"quantity":3.5}`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"weight":0.25}`, &ValidateError{ParamName: "weight", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out order
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
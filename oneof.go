@@ -0,0 +1,205 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ONE_OF_SELF makes oneof ask the struct itself for the allowed values, the
+// struct has to implement AllowedValuesProvider. Any other name starting
+// with ONE_OF_ENUM_PREFIX refers to an enum registered with RegisterEnum.
+const (
+	ONE_OF_SELF        = "@self"
+	ONE_OF_ENUM_PREFIX = "@"
+)
+
+var enums = struct {
+	sync.RWMutex
+	m map[string][]string
+}{
+	m: make(map[string][]string),
+}
+
+// RegisterEnum makes the String() values of an enum available as
+// oneof=@name. It panics if the name is already registered.
+func RegisterEnum(name string, values ...fmt.Stringer) {
+	if name == "" || len(values) == 0 {
+		panic("Enum must have a name and values")
+	}
+
+	allowed := make([]string, len(values))
+	for i, v := range values {
+		allowed[i] = v.String()
+	}
+
+	enums.Lock()
+	defer enums.Unlock()
+
+	if _, ok := enums.m[name]; ok {
+		panic(fmt.Sprintf("Enum '%s' already registered", name))
+	}
+
+	enums.m[name] = allowed
+}
+
+// AllowedValuesProvider is implemented by structs declaring the allowed
+// values of their fields tagged with oneof=@self. The argument is the
+// param name of the field.
+type AllowedValuesProvider interface {
+	AllowedValues(param string) []string
+}
+
+// checkOneOf serves both oneof and oneofci, the latter folding the case of
+// string values.
+func checkOneOf(tagName string, vParams FieldValidationParams, structField reflect.StructField, fValue, structValue reflect.Value, tagRawVal string) *ValidateError {
+	var allowed []string
+	if tagRawVal == ONE_OF_SELF {
+		provider, ok := structValue.Addr().Interface().(AllowedValuesProvider)
+		if !ok {
+			panic(fmt.Sprintf("Field '%s' uses '%s=%s' but '%s' does not implement AllowedValues",
+				structField.Name, tagName, ONE_OF_SELF, structValue.Type().Name()))
+		}
+
+		allowed = provider.AllowedValues(paramName(structValue.Type(), structField))
+	} else if strings.HasPrefix(tagRawVal, ONE_OF_ENUM_PREFIX) {
+		name := strings.TrimPrefix(tagRawVal, ONE_OF_ENUM_PREFIX)
+
+		enums.RLock()
+		values, ok := enums.m[name]
+		enums.RUnlock()
+		if !ok {
+			panic(fmt.Sprintf("Unknown enum '%s' on field '%s'", name, structField.Name))
+		}
+
+		allowed = values
+	} else {
+		allowed = strings.Fields(tagRawVal)
+	}
+
+	var val string
+	switch fValue.Kind() {
+	case reflect.String:
+		val = fValue.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val = strconv.FormatInt(fValue.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val = strconv.FormatUint(fValue.Uint(), 10)
+	default:
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a string or an integer", tagName, structField.Name))
+	}
+
+	fold := tagName == TAG_FIELD_ONE_OF_CI && fValue.Kind() == reflect.String
+	for _, v := range allowed {
+		if v == val || (fold && strings.EqualFold(v, val)) {
+			return nil
+		}
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_NOT_ALLOWED,
+		OriginalError: fmt.Errorf("Param '%s' is not one of the allowed values (%s)", vParams.Name, strings.Join(allowed, ", ")),
+	}
+}
+
+type lazySet struct {
+	sync.RWMutex
+	loader func() ([]string, error)
+	values map[string]struct{}
+}
+
+var lazySets = struct {
+	sync.RWMutex
+	m map[string]*lazySet
+}{
+	m: make(map[string]*lazySet),
+}
+
+// RegisterLazySet makes the values returned by loader available as
+// inset=name. The loader runs on the first validation referring to the set
+// and once it succeeds the set is kept. A loader error fails the validation
+// with VALIDATE_ERR_CODE_UNKNOWN and the next validation tries again. It
+// panics if the name is already registered.
+func RegisterLazySet(name string, loader func() ([]string, error)) {
+	if name == "" || loader == nil {
+		panic("Lazy set must have a name and a loader")
+	}
+
+	lazySets.Lock()
+	defer lazySets.Unlock()
+
+	if _, ok := lazySets.m[name]; ok {
+		panic(fmt.Sprintf("Lazy set '%s' already registered", name))
+	}
+
+	lazySets.m[name] = &lazySet{loader: loader}
+}
+
+// load returns the values of the set, calling the loader unless a previous
+// call succeeded. Concurrent first uses wait for a single loader call.
+func (s *lazySet) load() (map[string]struct{}, error) {
+	s.RLock()
+	values := s.values
+	s.RUnlock()
+	if values != nil {
+		return values, nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.values != nil {
+		return s.values, nil
+	}
+
+	loaded, err := s.loader()
+	if err != nil {
+		return nil, err
+	}
+
+	values = make(map[string]struct{}, len(loaded))
+	for _, v := range loaded {
+		values[v] = struct{}{}
+	}
+
+	s.values = values
+	return values, nil
+}
+
+func checkInSet(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, name string) *ValidateError {
+	mustBeString(TAG_FIELD_IN_SET, structField, fValue)
+
+	lazySets.RLock()
+	set, ok := lazySets.m[name]
+	lazySets.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Unknown lazy set '%s' on field '%s'", name, structField.Name))
+	}
+
+	values, err := set.load()
+	if err != nil {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_UNKNOWN,
+			OriginalError: fmt.Errorf("Unable to load the set '%s' of param '%s': %s", name, vParams.Name, err.Error()),
+		}
+	}
+
+	if _, ok := values[fValue.String()]; ok {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_NOT_ALLOWED,
+		OriginalError: fmt.Errorf("Param '%s' is not in the set '%s'", vParams.Name, name),
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type member struct {
+	Role string `validate:"name=role,oneof=@self"`
+}
+
+func (m *member) AllowedValues(param string) []string {
+	if param == "role" {
+		return []string{"admin", "user"}
+	}
+
+	return nil
+}
+
+func TestOneOfSelf(t *testing.T) {
+	type team struct {
+		Lead    member   `validate:"name=lead"`
+		Members []member `validate:"name=members"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"lead":{"role":"admin"},"members":[{"role":"user"}]}`, nil},
+		{`{"lead":{"role":"root"}}`, &ValidateError{ParamName: "lead.role", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"members":[{"role":"user"},{"role":"root"}]}`, &ValidateError{ParamName: "members[1].role", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out team
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	var m member
+	checkError(t, Validate(decodeInput(t, `{"role":"user"}`), &m), nil)
+	checkError(t, Validate(decodeInput(t, `{"role":"root"}`), &m), &ValidateError{ParamName: "role", Code: VALIDATE_ERR_CODE_NOT_ALLOWED})
+}
+
+var (
+	testCountriesCalls int32
+	testFlakyFail      bool
+)
+
+func init() {
+	RegisterLazySet("testcountries", func() ([]string, error) {
+		atomic.AddInt32(&testCountriesCalls, 1)
+		return []string{"UA", "PL"}, nil
+	})
+
+	RegisterLazySet("testflaky", func() ([]string, error) {
+		if testFlakyFail {
+			return nil, errors.New("config service unavailable")
+		}
+
+		return []string{"a"}, nil
+	})
+}
+
+// unloadLazySet drops the values a set has loaded, so that a test run again
+// starts from an unloaded set.
+func unloadLazySet(name string) {
+	lazySets.RLock()
+	set := lazySets.m[name]
+	lazySets.RUnlock()
+
+	set.Lock()
+	set.values = nil
+	set.Unlock()
+}
+
+func TestInSetLoadsOnce(t *testing.T) {
+	unloadLazySet("testcountries")
+	atomic.StoreInt32(&testCountriesCalls, 0)
+
+	type payload struct {
+		Country string `validate:"name=country,inset=testcountries"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var out payload
+			if err := Validate(decodeInput(t, `{"country":"UA"}`), &out); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			err := Validate(decodeInput(t, `{"country":"DE"}`), &out)
+			if valErr, ok := err.(*ValidateError); !ok || valErr.Code != VALIDATE_ERR_CODE_NOT_ALLOWED {
+				t.Errorf("expected NOT_ALLOWED, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&testCountriesCalls); calls != 1 {
+		t.Fatalf("loader ran %d times, want once", calls)
+	}
+}
+
+func TestInSetLoaderError(t *testing.T) {
+	unloadLazySet("testflaky")
+	testFlakyFail = true
+
+	type payload struct {
+		V string `validate:"name=v,inset=testflaky"`
+	}
+
+	var out payload
+	checkError(t, Validate(decodeInput(t, `{"v":"a"}`), &out), &ValidateError{ParamName: "v", Code: VALIDATE_ERR_CODE_UNKNOWN})
+
+	testFlakyFail = false
+	checkError(t, Validate(decodeInput(t, `{"v":"a"}`), &out), nil)
+}
+
+type testColor int
+
+const (
+	testColorRed testColor = iota
+	testColorGreen
+	testColorBlue
+)
+
+func (c testColor) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+func init() {
+	RegisterEnum("testcolor", testColorRed, testColorGreen, testColorBlue)
+}
+
+func TestOneOfEnum(t *testing.T) {
+	type paint struct {
+		Color string `validate:"name=color,oneof=@testcolor"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"color":"green"}`, nil},
+		{`{"color":"purple"}`, &ValidateError{ParamName: "color", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"color":"1"}`, &ValidateError{ParamName: "color", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out paint
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestOneOfCaseInsensitive(t *testing.T) {
+	type account struct {
+		Role    string `validate:"name=role,oneofci=admin user"`
+		Strict  string `validate:"name=strict,oneof=admin user"`
+		Retries int    `validate:"name=retries,oneofci=1 3"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"role":"ADMIN","retries":3}`, nil},
+		{`{"role":"User"}`, nil},
+		{`{"strict":"ADMIN"}`, &ValidateError{ParamName: "strict", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"role":"root"}`, &ValidateError{ParamName: "role", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"retries":2}`, &ValidateError{ParamName: "retries", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out account
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Option tunes a single validation call.
+type Option func(*options)
+
+type options struct {
+	ctx              context.Context
+	strictTypes      bool
+	networkChecks    bool
+	expected         map[string]interface{}
+	emptyStringAsNil bool
+	report           *Report
+	resolver         Resolver
+	flags            map[string]bool
+	consistentValues bool
+	overrides        map[string]FieldRules
+	overridesType    reflect.Type
+
+	collectAll bool
+	errors     []*ValidateError
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithStrictTypes rejects params whose JSON type does not match the kind of
+// the field, e.g. a quoted number sent for an int field.
+func WithStrictTypes() Option {
+	return func(o *options) {
+		o.strictTypes = true
+	}
+}
+
+// WithNetworkChecks enables the checks doing network I/O, such as
+// format=reachable. They are skipped unless this option is given.
+func WithNetworkChecks() Option {
+	return func(o *options) {
+		o.networkChecks = true
+	}
+}
+
+// WithExpected provides the values the eqexpected and increasing tags
+// compare against, keyed by the name given in the tag. Fields whose name has
+// no expected value are not compared.
+func WithExpected(expected map[string]interface{}) Option {
+	return func(o *options) {
+		o.expected = expected
+	}
+}
+
+// WithEmptyStringAsNil stores nil instead of a pointer to "" into *string
+// fields. Such a field is then handled as unset and its rules are skipped.
+func WithEmptyStringAsNil() Option {
+	return func(o *options) {
+		o.emptyStringAsNil = true
+	}
+}
+
+// WithConsistentValues makes ValidateRequest reject a param repeated with
+// differing values when it is bound to a scalar field, instead of taking
+// the first value.
+func WithConsistentValues() Option {
+	return func(o *options) {
+		o.consistentValues = true
+	}
+}
+
+func isEmptyStringPtr(fValue reflect.Value) bool {
+	return fValue.Kind() == reflect.Ptr && fValue.Type().Elem().Kind() == reflect.String &&
+		!fValue.IsNil() && fValue.Elem().String() == ""
+}
+
+// Report collects the findings of a validation which do not fail it.
+type Report struct {
+	Warnings []*ValidateError
+}
+
+// WithReport fills report with the warnings raised during the validation,
+// e.g. by values exceeding a softmax bound.
+func WithReport(report *Report) Option {
+	return func(o *options) {
+		o.report = report
+	}
+}
+
+// fail returns valErr to abort the validation, unless all the errors are
+// collected. In that case valErr is recorded and nil is returned so that the
+// validation goes on.
+func (o *options) fail(valErr *ValidateError) *ValidateError {
+	if valErr == nil || !o.collectAll {
+		return valErr
+	}
+
+	o.errors = append(o.errors, valErr)
+	return nil
+}
+
+func (o *options) warn(warning *ValidateError) {
+	if o.report != nil {
+		o.report.Warnings = append(o.report.Warnings, warning)
+	}
+}
+
+// Resolver reports whether value exists among the resources of the given
+// type, e.g. by looking it up in a database.
+type Resolver func(ctx context.Context, resourceType string, value interface{}) (bool, error)
+
+// WithResolver provides the resolver consulted by the exists tag.
+func WithResolver(resolver Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithFlags provides the feature flags consulted by the skipflag tag. A field
+// tagged skipflag=name is decoded without its rules unless flags[name] is
+// true, so a flag missing from the map counts as off.
+func WithFlags(flags map[string]bool) Option {
+	return func(o *options) {
+		o.flags = flags
+	}
+}
+
+// overridden merges the rules loaded into a Validator for the field over the
+// params decoded from its tag. Only the fields of the struct given to the
+// Validator are overridden. The cached params are left untouched.
+func (o *options) overridden(structType reflect.Type, structField reflect.StructField, vParams FieldValidationParams) FieldValidationParams {
+	if structType != o.overridesType {
+		return vParams
+	}
+
+	rules, ok := o.overrides[structField.Name]
+	if !ok {
+		return vParams
+	}
+
+	fields := make(map[string]string, len(vParams.Fields)+len(rules.Tags))
+	for k, v := range vParams.Fields {
+		fields[k] = v
+	}
+
+	for k, v := range rules.Tags {
+		fields[k] = v
+	}
+
+	return FieldValidationParams{Name: vParams.Name, Required: vParams.Required || rules.Required, Fields: fields}
+}
+
+// enabledRules drops the checks of a field disabled by its skipflag, only
+// its default is kept.
+func (o *options) enabledRules(vParams FieldValidationParams) FieldValidationParams {
+	flag, ok := vParams.Fields[TAG_FIELD_SKIP_FLAG]
+	if !ok || o.flags[flag] {
+		return vParams
+	}
+
+	fields := make(map[string]string)
+	if v, ok := vParams.Fields[TAG_FIELD_DEFAULT]; ok {
+		fields[TAG_FIELD_DEFAULT] = v
+	}
+
+	return FieldValidationParams{Name: vParams.Name, Fields: fields}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func checkJSONType(paramName string, raw json.RawMessage, fieldType reflect.Type) *ValidateError {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if reflect.PtrTo(fieldType).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] == 'n' {
+		return nil
+	}
+
+	var expected byte
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9') {
+			return nil
+		}
+	case reflect.String:
+		expected = '"'
+	case reflect.Bool:
+		if raw[0] == 't' || raw[0] == 'f' {
+			return nil
+		}
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			expected = '"'
+		} else {
+			expected = '['
+		}
+	case reflect.Array:
+		expected = '['
+	case reflect.Map, reflect.Struct:
+		expected = '{'
+	default:
+		return nil
+	}
+
+	if expected != 0 && raw[0] == expected {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     paramName,
+		Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+		OriginalError: fmt.Errorf("Param '%s' has an unexpected JSON type for %s", paramName, fieldType.Kind()),
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestWithStrictTypes(t *testing.T) {
+	type item struct {
+		Count  int      `validate:"name=count"`
+		Title  string   `validate:"name=title"`
+		Active bool     `validate:"name=active"`
+		Tags   []string `validate:"name=tags"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"count":3,"title":"a","active":true,"tags":["x"]}`, nil},
+		{`{"count":null}`, nil},
+		{`{"count":"3"}`, &ValidateError{ParamName: "count", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{`{"title":3}`, &ValidateError{ParamName: "title", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{`{"active":"true"}`, &ValidateError{ParamName: "active", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{`{"tags":"x"}`, &ValidateError{ParamName: "tags", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out item
+			checkError(t, Validate(decodeInput(t, tt.input), &out, WithStrictTypes()), tt.want)
+		})
+	}
+}
+
+func TestWithEmptyStringAsNil(t *testing.T) {
+	type profile struct {
+		Nickname *string `validate:"name=nickname,minLen=3"`
+	}
+
+	var out profile
+	checkError(t, Validate(decodeInput(t, `{"nickname":""}`), &out, WithEmptyStringAsNil()), nil)
+	if out.Nickname != nil {
+		t.Fatalf("expected a nil nickname, got %q", *out.Nickname)
+	}
+
+	out = profile{}
+	checkError(t, Validate(decodeInput(t, `{"nickname":"neo"}`), &out, WithEmptyStringAsNil()), nil)
+	if out.Nickname == nil || *out.Nickname != "neo" {
+		t.Fatalf("expected a pointer to the nickname, got %v", out.Nickname)
+	}
+
+	out = profile{}
+	checkError(t, Validate(decodeInput(t, `{"nickname":""}`), &out),
+		&ValidateError{ParamName: "nickname", Code: VALIDATE_ERR_CODE_TOO_SHORT})
+}
+
+func TestWithFlags(t *testing.T) {
+	type signup struct {
+		Referral string `validate:"name=referral,required,minLen=8,skipflag=referrals"`
+		Plan     string `validate:"name=plan,oneof=free pro,default=free,skipflag=plans"`
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		flags map[string]bool
+		want  *ValidateError
+	}{
+		{"enabled", `{"referral":"ABCDEFGH"}`, map[string]bool{"referrals": true}, nil},
+		{"enabled short", `{"referral":"ABC"}`, map[string]bool{"referrals": true}, &ValidateError{ParamName: "referral", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{"enabled missing", `{}`, map[string]bool{"referrals": true}, &ValidateError{ParamName: "referral", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{"disabled", `{"referral":"ABC","plan":"gold"}`, map[string]bool{"referrals": false}, nil},
+		{"no flags", `{}`, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out signup
+			checkError(t, Validate(decodeInput(t, tt.input), &out, WithFlags(tt.flags)), tt.want)
+		})
+	}
+
+	var out signup
+	checkError(t, Validate(decodeInput(t, `{}`), &out, WithFlags(nil)), nil)
+	if out.Plan != "free" {
+		t.Fatalf("expected the default plan to be kept, got '%s'", out.Plan)
+	}
+}
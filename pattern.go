@@ -0,0 +1,85 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"unicode"
+)
+
+var patternCache sync.Map
+
+func compilePattern(tagName string, structField reflect.StructField, pattern string) *regexp.Regexp {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to compile '%s' tag of field '%s': %s", tagName, structField.Name, err.Error()))
+	}
+
+	patternCache.Store(pattern, re)
+	return re
+}
+
+func checkPattern(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, pattern string) *ValidateError {
+	mustBeString(TAG_FIELD_PATTERN, structField, fValue)
+
+	if compilePattern(TAG_FIELD_PATTERN, structField, pattern).MatchString(fValue.String()) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' does not match the pattern '%s'", vParams.Name, pattern),
+	}
+}
+
+// matchMask matches s rune by rune against a mask where '#' stands for a
+// digit, 'A' for a letter and any other rune for itself.
+func matchMask(s, mask string) bool {
+	runes, maskRunes := []rune(s), []rune(mask)
+	if len(runes) != len(maskRunes) {
+		return false
+	}
+
+	for i, m := range maskRunes {
+		switch m {
+		case '#':
+			if !unicode.IsDigit(runes[i]) {
+				return false
+			}
+		case 'A':
+			if !unicode.IsLetter(runes[i]) {
+				return false
+			}
+		default:
+			if runes[i] != m {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func checkMask(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, mask string) *ValidateError {
+	mustBeString(TAG_FIELD_MASK, structField, fValue)
+
+	if matchMask(fValue.String(), mask) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' does not match the mask '%s'", vParams.Name, mask),
+	}
+}
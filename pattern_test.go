@@ -0,0 +1,112 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	type card struct {
+		Plate string `validate:"name=plate,mask=AA ####"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"plate":"KA 1234"}`, nil},
+		{`{"plate":"ФБ 0001"}`, nil},
+		{`{"plate":"K1 1234"}`, &ValidateError{ParamName: "plate", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"plate":"KA-1234"}`, &ValidateError{ParamName: "plate", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"plate":"KA 123"}`, &ValidateError{ParamName: "plate", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out card
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestPattern(t *testing.T) {
+	type payload struct {
+		Code  string            `validate:"name=code,maxLen=3,pattern=^[a-z]{2,3}$"`
+		Words []string          `validate:"name=words,dive,pattern=^[a-z]{1,2}$"`
+		Attrs map[string]string `validate:"name=attrs,valuepattern=^[0-9]{1,2}$"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"code":"ab","words":["a","bc"],"attrs":{"x":"12"}}`, nil},
+		{`{"code":"abc"}`, nil},
+		{`{"code":"a"}`, &ValidateError{ParamName: "code", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"code":"AB"}`, &ValidateError{ParamName: "code", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"words":["a","abc"]}`, &ValidateError{ParamName: "words[1]", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"attrs":{"x":"123"}}`, &ValidateError{ParamName: "attrs[x]", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out payload
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestPatternRulesRoundTrip(t *testing.T) {
+	type payload struct {
+		Code  string   `validate:"name=code,pattern=^[a-z]{2,3}$"`
+		Words []string `validate:"name=words,dive,minLen=1,pattern=^[a-z]{1,2}$"`
+	}
+
+	data, err := MarshalRules(payload{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := UnmarshalRules(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := rulesOf(payload{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := rules.structType()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := rulesOf(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reloaded, want) {
+		t.Fatalf("rules changed on reload: %+v != %+v", reloaded, want)
+	}
+}
+
+func TestPatternNotLast(t *testing.T) {
+	type payload struct {
+		Code string `validate:"name=code,pattern=^x$,required"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("a tag field following pattern did not panic")
+		}
+	}()
+
+	var out payload
+	Validate(decodeInput(t, `{"code":"x"}`), &out)
+}
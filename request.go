@@ -0,0 +1,108 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ValidateRequest validates the query params and the form body of r into
+// outputStruct. The string values are encoded to JSON according to the
+// field they are bound to, ?age=30 decodes into an int field and repeated
+// params into a slice field.
+func ValidateRequest(r *http.Request, outputStruct interface{}, opts ...Option) error {
+	outValue := checkOutputStruct(outputStruct)
+
+	if err := r.ParseForm(); err != nil {
+		return &ValidateError{
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	o := newOptions(opts)
+	fieldTypes := paramTypes(outValue.Elem().Type())
+	inputData := make(map[string]*json.RawMessage, len(r.Form))
+	for name, values := range r.Form {
+		if o.consistentValues && !isSliceParam(fieldTypes[name]) && !allEqual(values) {
+			return &ValidateError{
+				ParamName:     name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' is repeated with differing values", name),
+			}
+		}
+
+		raw := formValue(fieldTypes[name], values)
+		inputData[name] = &raw
+	}
+
+	return Validate(inputData, outputStruct, opts...)
+}
+
+func paramTypes(structType reflect.Type) map[string]reflect.Type {
+	fields := taggedFields(structType)
+	types := make(map[string]reflect.Type, len(fields))
+	for _, tf := range fields {
+		types[tf.params.Name] = structType.Field(tf.index).Type
+	}
+
+	return types
+}
+
+// formValue encodes the values of a param: numbers and bools are kept as bare
+// tokens for the fields of these kinds, anything else, null included, is
+// quoted. A scalar field takes the first of repeated values.
+func formValue(t reflect.Type, values []string) json.RawMessage {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if isSliceParam(t) {
+		elems := make([]json.RawMessage, 0, len(values))
+		for _, v := range values {
+			elems = append(elems, formValue(t.Elem(), []string{v}))
+		}
+
+		raw, _ := json.Marshal(elems)
+		return raw
+	}
+
+	v := values[0]
+	if t != nil && isBareKind(t.Kind()) && v != "null" && json.Valid([]byte(v)) && !strings.ContainsAny(v, `"[{`) {
+		return json.RawMessage(v)
+	}
+
+	raw, _ := json.Marshal(v)
+	return raw
+}
+
+// isSliceParam tells whether a param bound to a field of type t takes all
+// of its repeated values.
+func isSliceParam(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t != nil && t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+func allEqual(values []string) bool {
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isBareKind(kind reflect.Kind) bool {
+	return kind == reflect.Bool || isIntKind(kind) || isUintKind(kind) ||
+		kind == reflect.Float32 || kind == reflect.Float64
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestValidateRequest(t *testing.T) {
+	type query struct {
+		Age    int      `validate:"name=age,required,min=1"`
+		Admin  bool     `validate:"name=admin"`
+		Name   string   `validate:"name=name"`
+		Tags   []string `validate:"name=tag"`
+		Scores []int    `validate:"name=score"`
+	}
+
+	r := httptest.NewRequest("GET", "/?age=30&admin=true&name=42&tag=a&tag=b&score=1&score=2", nil)
+
+	var out query
+	checkError(t, ValidateRequest(r, &out), nil)
+
+	if out.Age != 30 || !out.Admin || out.Name != "42" {
+		t.Errorf("unexpected scalars: %+v", out)
+	}
+
+	if strings.Join(out.Tags, ",") != "a,b" || len(out.Scores) != 2 || out.Scores[1] != 2 {
+		t.Errorf("unexpected slices: %+v", out)
+	}
+
+	tests := []struct {
+		query string
+		want  *ValidateError
+	}{
+		{"age=0", &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{"age=abc", &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{"age=null", &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{"admin=null&age=1", &ValidateError{ParamName: "admin", Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+		{"name=x", &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var out query
+			checkError(t, ValidateRequest(httptest.NewRequest("GET", "/?"+tt.query, nil), &out), tt.want)
+		})
+	}
+}
+
+func TestValidateRequestForm(t *testing.T) {
+	type form struct {
+		Age int `validate:"name=age,required"`
+	}
+
+	body := url.Values{"age": {"7"}}.Encode()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var out form
+	checkError(t, ValidateRequest(r, &out), nil)
+	if out.Age != 7 {
+		t.Fatalf("age = %d, want 7", out.Age)
+	}
+}
+
+func TestValidateRequestConsistentValues(t *testing.T) {
+	type query struct {
+		Sort string   `validate:"name=sort"`
+		Tags []string `validate:"name=tag"`
+	}
+
+	tests := []struct {
+		query string
+		want  *ValidateError
+	}{
+		{"sort=name&sort=name&tag=a&tag=b", nil},
+		{"sort=name&sort=date", &ValidateError{ParamName: "sort", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var out query
+			checkError(t, ValidateRequest(httptest.NewRequest("GET", "/?"+tt.query, nil), &out, WithConsistentValues()), tt.want)
+		})
+	}
+
+	var out query
+	checkError(t, ValidateRequest(httptest.NewRequest("GET", "/?sort=name&sort=date", nil), &out), nil)
+	if out.Sort != "name" {
+		t.Fatalf("expected the first value without the option, got '%s'", out.Sort)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldRules are the parsed validate tag of a single struct field.
+type FieldRules struct {
+	Field    string            `json:"field"`
+	Type     string            `json:"type"`
+	Name     string            `json:"name"`
+	Required bool              `json:"required,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// Rules are the validation rules of a struct detached from its Go type. They
+// are produced by MarshalRules and loaded back with UnmarshalRules.
+type Rules struct {
+	Fields []FieldRules `json:"fields"`
+}
+
+var ruleBaseTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		"", false, 0, int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), time.Time{}, time.Duration(0),
+		json.RawMessage{}, []interface{}{}, map[string]interface{}{},
+	} {
+		t := reflect.TypeOf(v)
+		ruleBaseTypes[t.String()] = t
+	}
+}
+
+// ruleType resolves the type names used in Rules. Besides the base types
+// it accepts pointers, slices and string keyed maps of them.
+func ruleType(name string) (reflect.Type, bool) {
+	if t, ok := ruleBaseTypes[name]; ok {
+		return t, true
+	}
+
+	switch {
+	case strings.HasPrefix(name, "*"):
+		if elem, ok := ruleType(name[1:]); ok {
+			return reflect.PtrTo(elem), true
+		}
+	case strings.HasPrefix(name, "[]"):
+		if elem, ok := ruleType(name[2:]); ok {
+			return reflect.SliceOf(elem), true
+		}
+	case strings.HasPrefix(name, "map[string]"):
+		if elem, ok := ruleType(name[len("map[string]"):]); ok {
+			return reflect.MapOf(reflect.TypeOf(""), elem), true
+		}
+	}
+
+	return nil, false
+}
+
+// MarshalRules serializes the validate tags of structType, a struct value or
+// its reflect.Type, to JSON. Only the fields of the types known to Rules can
+// be serialized.
+func MarshalRules(structType interface{}) ([]byte, error) {
+	rules, err := rulesOf(structType)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rules)
+}
+
+func rulesOf(structType interface{}) (Rules, error) {
+	t := structTypeOf(structType)
+	if t == nil {
+		return Rules{}, fmt.Errorf("Rules can only be built for a struct type")
+	}
+
+	rules := Rules{Fields: make([]FieldRules, 0, t.NumField())}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tagValue, ok := structField.Tag.Lookup(VALIDATE_TAG_NAME)
+		if !ok {
+			continue
+		}
+
+		if _, ok := ruleType(structField.Type.String()); !ok {
+			return Rules{}, fmt.Errorf("Field '%s' has unsupported type '%s'", structField.Name, structField.Type)
+		}
+
+		vParams := decodeTagFields(splitTagFields(tagValue))
+		rules.Fields = append(rules.Fields, FieldRules{
+			Field:    structField.Name,
+			Type:     structField.Type.String(),
+			Name:     vParams.Name,
+			Required: vParams.Required,
+			Tags:     vParams.Fields,
+		})
+	}
+
+	return rules, nil
+}
+
+// UnmarshalRules loads the rules serialized by MarshalRules. The tags are
+// checked up front, an unknown tag field or a malformed bound is an error.
+func UnmarshalRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, err
+	}
+
+	t, err := rules.structType()
+	if err != nil {
+		return Rules{}, err
+	}
+
+	for i, f := range rules.Fields {
+		if err := checkRuleTags(t.Field(i)); err != nil {
+			return Rules{}, fmt.Errorf("Field '%s': %s", f.Field, err.Error())
+		}
+	}
+
+	return rules, nil
+}
+
+// checkRuleTags reports what would make the validation of a field built
+// from rules panic: an undecodable tag, an unknown tag field or a malformed
+// bound.
+func checkRuleTags(structField reflect.StructField) error {
+	vParams, err := decodeRuleTag(splitTagFields(structField.Tag.Get(VALIDATE_TAG_NAME)))
+	if err != nil {
+		return err
+	}
+
+	return checkRuleFields(vParams.Fields, structField.Type)
+}
+
+// decodeRuleTag is decodeTagFields reporting a malformed tag as an error.
+func decodeRuleTag(tagFieldsRaw []string) (vParams FieldValidationParams, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return decodeTagFields(tagFieldsRaw), nil
+}
+
+func checkRuleFields(fields map[string]string, t reflect.Type) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	tagNames := make([]string, 0, len(fields))
+	for tagName := range fields {
+		tagNames = append(tagNames, tagName)
+	}
+	sort.Strings(tagNames)
+
+	for _, tagName := range tagNames {
+		if !tagFieldNames[tagName] {
+			return fmt.Errorf("Unknown tag field: '%s'", tagName)
+		}
+
+		tagRawVal := fields[tagName]
+		var err error
+		switch tagName {
+		case TAG_FIELD_MIN, TAG_FIELD_MAX, TAG_FIELD_GT, TAG_FIELD_GTE, TAG_FIELD_LT, TAG_FIELD_LTE:
+			switch kind := t.Kind(); {
+			case isIntKind(kind):
+				_, err = strconv.ParseInt(tagRawVal, 10, 64)
+			case isUintKind(kind):
+				_, err = strconv.ParseUint(tagRawVal, 10, 64)
+			case kind == reflect.Float32 || kind == reflect.Float64:
+				_, err = strconv.ParseFloat(tagRawVal, 64)
+			default:
+				return fmt.Errorf("Tag '%s' cannot be applied to a %s", tagName, t)
+			}
+		case TAG_FIELD_MIN_LEN, TAG_FIELD_MAX_LEN, TAG_FIELD_LEN, TAG_FIELD_MAX_BYTES,
+			TAG_FIELD_MIN_VALUES, TAG_FIELD_MAX_VALUES, TAG_FIELD_DISPLAY_WIDTH, TAG_FIELD_MAX_TEXT:
+			_, err = strconv.ParseUint(tagRawVal, 10, 64)
+		case TAG_FIELD_SUM_MIN, TAG_FIELD_SUM_MAX:
+			_, err = strconv.ParseFloat(tagRawVal, 64)
+		case TAG_FIELD_DIVE, TAG_FIELD_HEAD_RULE:
+			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return fmt.Errorf("Tag '%s' cannot be applied to a %s", tagName, t)
+			}
+
+			elemParams, err := decodeRuleTag(strings.Split(tagRawVal, TAG_RULE_SEPARATOR))
+			if err == nil {
+				err = checkRuleFields(elemParams.Fields, t.Elem())
+			}
+
+			if err != nil {
+				return fmt.Errorf("Tag '%s': %s", tagName, err.Error())
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("Unable to parse '%s' tag: %s", tagName, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// structType builds a struct type carrying the rules as validate tags, so
+// that they are checked exactly like the tags of a Go type.
+func (r Rules) structType() (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, len(r.Fields))
+	for _, f := range r.Fields {
+		if !token.IsExported(f.Field) {
+			return nil, fmt.Errorf("Field '%s' is not an exported identifier", f.Field)
+		}
+
+		if f.Name == "" {
+			return nil, fmt.Errorf("Field '%s' has no param name", f.Field)
+		}
+
+		t, ok := ruleType(f.Type)
+		if !ok {
+			return nil, fmt.Errorf("Field '%s' has unsupported type '%s'", f.Field, f.Type)
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: f.Field,
+			Type: t,
+			Tag:  reflect.StructTag(VALIDATE_TAG_NAME + ":" + strconv.Quote(f.tag())),
+		})
+	}
+
+	return reflect.StructOf(fields), nil
+}
+
+func takesRest(tagName string) bool {
+	return tagName == TAG_FIELD_PATTERN || tagName == TAG_FIELD_VALUE_PATTERN || tagName == TAG_FIELD_DIVE
+}
+
+func (f FieldRules) tag() string {
+	tagFields := []string{TAG_FIELD_NAME + "=" + f.Name}
+	if f.Required {
+		tagFields = append(tagFields, TAG_FIELD_REQUIRED)
+	}
+
+	keys := make([]string, 0, len(f.Tags))
+	for k := range f.Tags {
+		keys = append(keys, k)
+	}
+
+	// The fields taking the rest of the tag go last, see splitTagFields
+	sort.Slice(keys, func(i, j int) bool {
+		if takesRest(keys[i]) != takesRest(keys[j]) {
+			return takesRest(keys[j])
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		switch {
+		case k == TAG_FIELD_DIVE && f.Tags[k] != "":
+			tagFields = append(tagFields, TAG_FIELD_DIVE, strings.ReplaceAll(f.Tags[k], TAG_RULE_SEPARATOR, ","))
+		case f.Tags[k] == "":
+			tagFields = append(tagFields, k)
+		default:
+			tagFields = append(tagFields, k+"="+f.Tags[k])
+		}
+	}
+
+	return strings.Join(tagFields, ",")
+}
+
+// Validate checks inputData against the rules and returns the decoded params
+// keyed by their names.
+func (r Rules) Validate(inputData map[string]*json.RawMessage, opts ...Option) (map[string]interface{}, error) {
+	return r.ValidateContext(context.Background(), inputData, opts...)
+}
+
+// ValidateContext is Rules.Validate bound to ctx.
+func (r Rules) ValidateContext(ctx context.Context, inputData map[string]*json.RawMessage, opts ...Option) (map[string]interface{}, error) {
+	t, err := r.structType()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	outValue := reflect.New(t)
+	if err := ValidateContext(ctx, inputData, outValue.Interface(), opts...); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(r.Fields))
+	for i, f := range r.Fields {
+		values[f.Name] = outValue.Elem().Field(i).Interface()
+	}
+
+	return values, nil
+}
+
+// Validator validates structs with rules loaded at runtime on top of their
+// validate tags, e.g. from a config service.
+type Validator struct {
+	mu        sync.RWMutex
+	overrides map[string]FieldRules
+}
+
+// NewValidator returns a Validator without loaded rules, it validates like
+// Validate until LoadRules is called.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// LoadRules reads rules in the format of MarshalRules and replaces the ones
+// loaded before. A rule applies to the field of the validated struct whose
+// Go name matches its Field: its tags override the tags of the field with
+// the same name, and it can make the field required. The fields of nested
+// structs keep their own tags.
+func (v *Validator) LoadRules(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	rules, err := UnmarshalRules(data)
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[string]FieldRules, len(rules.Fields))
+	for _, f := range rules.Fields {
+		overrides[f.Field] = f
+	}
+
+	v.mu.Lock()
+	v.overrides = overrides
+	v.mu.Unlock()
+
+	return nil
+}
+
+// Validate is Validate applying the loaded rules.
+func (v *Validator) Validate(inputData map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) error {
+	return v.ValidateContext(context.Background(), inputData, outputStruct, opts...)
+}
+
+// ValidateContext is Validator.Validate bound to ctx.
+func (v *Validator) ValidateContext(ctx context.Context, inputData map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) error {
+	v.mu.RLock()
+	overrides := v.overrides
+	v.mu.RUnlock()
+
+	return ValidateContext(ctx, inputData, outputStruct, append(opts, func(o *options) {
+		o.overrides = overrides
+		o.overridesType = structTypeOf(outputStruct)
+	})...)
+}
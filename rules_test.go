@@ -0,0 +1,183 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRulesRoundTrip(t *testing.T) {
+	type profile struct {
+		Login   string            `validate:"name=login,required,minLen=3,maxLen=16"`
+		Role    string            `validate:"name=role,oneof=admin user,default=user"`
+		Age     *int              `validate:"name=age,min=18,max=130"`
+		Tags    []string          `validate:"name=tags,maxLen=5"`
+		Limits  map[string]int    `validate:"name=limits,maxvalues=3"`
+		Enabled bool              `validate:"name=enabled,default=true"`
+		Labels  map[string]string `validate:"name=labels,valuepattern=^[a-z]+$"`
+		Ignored string
+	}
+
+	data, err := MarshalRules(profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := UnmarshalRules(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := rulesOf(profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("rules changed on unmarshal: %+v != %+v", rules, want)
+	}
+
+	got, err := rules.structType()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := rulesOf(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reloaded, want) {
+		t.Fatalf("rules changed on reload: %+v != %+v", reloaded, want)
+	}
+
+	params, err := rules.Validate(decodeInput(t, `{"login":"neo","age":30,"tags":["a"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params["role"] != "user" || params["enabled"] != true {
+		t.Fatalf("defaults were not applied: %v", params)
+	}
+
+	_, err = rules.Validate(decodeInput(t, `{"login":"neo","age":12}`))
+	checkError(t, err, &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_TOO_SMALL})
+}
+
+func TestUnmarshalRulesErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"fields":[{"field":"lower","type":"string","name":"x"}]}`,
+		`{"fields":[{"field":"X","type":"string"}]}`,
+		`{"fields":[{"field":"X","type":"chan int","name":"x"}]}`,
+		`{"fields":`,
+		`{"fields":[{"field":"X","type":"int","name":"x","tags":{"maxx":"5"}}]}`,
+		`{"fields":[{"field":"X","type":"int","name":"x","tags":{"max":"abc"}}]}`,
+		`{"fields":[{"field":"X","type":"uint","name":"x","tags":{"min":"-1"}}]}`,
+		`{"fields":[{"field":"X","type":"string","name":"x","tags":{"max":"5"}}]}`,
+		`{"fields":[{"field":"X","type":"string","name":"x","tags":{"maxLen":"five"}}]}`,
+		`{"fields":[{"field":"X","type":"string","name":"x","tags":{"":"5"}}]}`,
+		`{"fields":[{"field":"X","type":"string","name":"x","tags":{"maxLen":"5,maxx"}}]}`,
+		`{"fields":[{"field":"X","type":"[]int","name":"x","tags":{"dive":"min=1;maxx=9"}}]}`,
+		`{"fields":[{"field":"X","type":"[]int","name":"x","tags":{"headrule":"max=z"}}]}`,
+	} {
+		if _, err := UnmarshalRules([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
+
+func TestValidatorLoadRules(t *testing.T) {
+	type order struct {
+		Quantity int    `validate:"name=quantity,min=1,max=100"`
+		Note     string `validate:"name=note"`
+	}
+
+	v := NewValidator()
+
+	var out order
+	if err := v.Validate(decodeInput(t, `{"quantity":50}`), &out); err != nil {
+		t.Fatalf("unexpected error before loading rules: %v", err)
+	}
+
+	config := `{"fields":[
+		{"field":"Quantity","type":"int","name":"quantity","tags":{"max":"10"}},
+		{"field":"Note","type":"string","name":"note","required":true}
+	]}`
+	if err := v.LoadRules(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"quantity":10,"note":"asap"}`, nil},
+		{`{"quantity":50,"note":"asap"}`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"quantity":0,"note":"asap"}`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"quantity":5}`, &ValidateError{ParamName: "note", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out order
+			checkError(t, v.Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+
+	out = order{}
+	if err := Validate(decodeInput(t, `{"quantity":50}`), &out); err != nil {
+		t.Fatalf("the loaded rules leaked into Validate: %v", err)
+	}
+
+	for _, config := range []string{
+		`{"fields":[{"field":"Quantity","type":"chan int","name":"quantity"}]}`,
+		`{"fields":[{"field":"Quantity","type":"int","name":"quantity","tags":{"maxx":"5"}}]}`,
+		`{"fields":[{"field":"Quantity","type":"int","name":"quantity","tags":{"max":"abc"}}]}`,
+	} {
+		if err := v.LoadRules(strings.NewReader(config)); err == nil {
+			t.Errorf("bad rules were loaded: %s", config)
+		}
+	}
+
+	// The rules loaded last stay in place
+	out = order{}
+	checkError(t, v.Validate(decodeInput(t, `{"quantity":50,"note":"asap"}`), &out),
+		&ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_TOO_BIG})
+}
+
+func TestValidatorLoadRulesNested(t *testing.T) {
+	type limit struct {
+		Max int `validate:"name=max,max=100"`
+	}
+
+	type quota struct {
+		Max    int     `validate:"name=max,max=100"`
+		Inner  limit   `validate:"name=inner"`
+		Others []limit `validate:"name=others"`
+	}
+
+	v := NewValidator()
+	if err := v.LoadRules(strings.NewReader(`{"fields":[{"field":"Max","type":"int","name":"max","tags":{"max":"5"}}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"max":5,"inner":{"max":50},"others":[{"max":50}]}`, nil},
+		{`{"max":50}`, &ValidateError{ParamName: "max", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"inner":{"max":500}}`, &ValidateError{ParamName: "inner.max", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out quota
+			checkError(t, v.Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var secrets = struct {
+	sync.RWMutex
+	m map[string][]byte
+}{
+	m: make(map[string][]byte),
+}
+
+// RegisterSecret stores a key referenced by name from the tags verifying
+// signatures or ciphertexts. It panics if the name is already registered.
+func RegisterSecret(name string, key []byte) {
+	if name == "" || len(key) == 0 {
+		panic("Secret must have a name and a key")
+	}
+
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	if _, ok := secrets.m[name]; ok {
+		panic(fmt.Sprintf("Secret '%s' already registered", name))
+	}
+
+	secrets.m[name] = append([]byte(nil), key...)
+}
+
+func lookupSecret(tagName string, structField reflect.StructField, name string) []byte {
+	secrets.RLock()
+	key, ok := secrets.m[name]
+	secrets.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' refers to unknown secret '%s'", tagName, structField.Name, name))
+	}
+
+	return key
+}
+
+func bytesOf(v reflect.Value) ([]byte, bool) {
+	switch {
+	case v.Kind() == reflect.String:
+		return []byte(v.String()), true
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return v.Bytes(), true
+	}
+
+	return nil, false
+}
+
+// checkHMAC expects the field to hold the hex encoded HMAC-SHA256 of the
+// referenced param, tagged as hmac=secretName:otherParam.
+func checkHMAC(pf presentField, present []presentField, tagRawVal string) *ValidateError {
+	mustBeString(TAG_FIELD_HMAC, pf.field, pf.value)
+
+	parts := strings.SplitN(tagRawVal, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as secret:param", TAG_FIELD_HMAC, pf.field.Name))
+	}
+
+	key := lookupSecret(TAG_FIELD_HMAC, pf.field, parts[0])
+
+	valErr := &ValidateError{
+		ParamName:     pf.params.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid signature of '%s'", pf.params.Name, parts[1]),
+	}
+
+	signed, ok := findPresent(present, parts[1])
+	if !ok {
+		return valErr
+	}
+
+	data, ok := bytesOf(signed)
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' of field '%s' refers to '%s' which is not a string or bytes",
+			TAG_FIELD_HMAC, pf.field.Name, parts[1]))
+	}
+
+	signature, err := hex.DecodeString(pf.value.String())
+	if err != nil {
+		return valErr
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return valErr
+	}
+
+	return nil
+}
+
+// checkDecryptable expects the field to hold the base64 encoded nonce and
+// AES-GCM ciphertext sealed with the named secret. The plaintext is dropped.
+func checkDecryptable(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, secretName string) *ValidateError {
+	mustBeString(TAG_FIELD_DECRYPTABLE, structField, fValue)
+
+	block, err := aes.NewCipher(lookupSecret(TAG_FIELD_DECRYPTABLE, structField, secretName))
+	if err != nil {
+		panic(fmt.Sprintf("Secret '%s' is not a valid AES key: %s", secretName, err.Error()))
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(fValue.String())
+	if err == nil && len(sealed) >= gcm.NonceSize() {
+		nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+		if _, err := gcm.Open(nil, nonce, ciphertext, nil); err == nil {
+			return nil
+		}
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' cannot be decrypted with '%s'", vParams.Name, secretName),
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+var testCardKey = []byte("0123456789abcdef0123456789abcdef")
+
+func init() {
+	RegisterSecret("testwebhook", []byte("webhook-key"))
+	RegisterSecret("testcard", testCardKey)
+}
+
+func TestHMAC(t *testing.T) {
+	type webhook struct {
+		Body      string `validate:"name=body"`
+		Signature string `validate:"name=signature,hmac=testwebhook:body"`
+	}
+
+	mac := hmac.New(sha256.New, []byte("webhook-key"))
+	mac.Write([]byte("hello"))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{fmt.Sprintf(`{"body":"hello","signature":"%s"}`, signature), nil},
+		{fmt.Sprintf(`{"body":"hellO","signature":"%s"}`, signature), &ValidateError{ParamName: "signature", Code: VALIDATE_ERR_CODE_INVALID}},
+		{fmt.Sprintf(`{"signature":"%s"}`, signature), &ValidateError{ParamName: "signature", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"body":"hello","signature":"not hex"}`, &ValidateError{ParamName: "signature", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out webhook
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestDecryptable(t *testing.T) {
+	type payment struct {
+		Card string `validate:"name=card,decryptable=testcard"`
+	}
+
+	block, err := aes.NewCipher(testCardKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte("4111111111111111"), nil)
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name string
+		card string
+		want *ValidateError
+	}{
+		{"sealed", base64.StdEncoding.EncodeToString(sealed), nil},
+		{"tampered", base64.StdEncoding.EncodeToString(tampered), &ValidateError{ParamName: "card", Code: VALIDATE_ERR_CODE_INVALID}},
+		{"short", base64.StdEncoding.EncodeToString(nonce[:4]), &ValidateError{ParamName: "card", Code: VALIDATE_ERR_CODE_INVALID}},
+		{"plain", "4111111111111111", &ValidateError{ParamName: "card", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out payment
+			checkError(t, Validate(decodeInput(t, fmt.Sprintf(`{"card":"%s"}`, tt.card)), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var slugRegexp = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+func checkSlug(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) *ValidateError {
+	mustBeString(TAG_FIELD_FORMAT, structField, fValue)
+
+	if slugRegexp.MatchString(fValue.String()) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' is not a valid slug", vParams.Name),
+	}
+}
+
+var slugFolds = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e", "ì", "i", "í", "i",
+	"î", "i", "ï", "i", "ñ", "n", "ò", "o", "ó", "o", "ô", "o", "õ", "o",
+	"ö", "o", "ø", "o", "œ", "oe", "ß", "ss", "ù", "u", "ú", "u", "û", "u",
+	"ü", "u", "ý", "y", "ÿ", "y",
+)
+
+// makeSlug lowercases s, folds the common Latin accents and joins the
+// remaining ASCII letters and digits with hyphens.
+func makeSlug(s string) string {
+	s = slugFolds.Replace(strings.ToLower(s))
+
+	var b strings.Builder
+	hyphen := false
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+
+	return b.String()
+}
+
+// fillSlugs generates the absent slugfrom fields from their source params
+// once all the fields are decoded, then checks them as if they were given.
+func fillSlugs(slugs, present []presentField, structValue reflect.Value, o *options) ([]presentField, *ValidateError) {
+	for _, sf := range slugs {
+		source := sf.params.Fields[TAG_FIELD_SLUG_FROM]
+		sourceValue, ok := findPresent(present, source)
+		if !ok {
+			if sf.params.Required {
+				if valErr := o.fail(&ValidateError{
+					ParamName:     sf.params.Name,
+					Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+					OriginalError: fmt.Errorf("Param '%s' is required", sf.params.Name),
+				}); valErr != nil {
+					return present, valErr
+				}
+			}
+
+			continue
+		}
+
+		if sourceValue.Kind() != reflect.String {
+			panic(fmt.Sprintf("Tag '%s' of field '%s' refers to '%s' which is not a string",
+				TAG_FIELD_SLUG_FROM, sf.field.Name, source))
+		}
+
+		fValue := sf.value
+		if fValue.Kind() == reflect.Ptr {
+			fValue.Set(reflect.New(fValue.Type().Elem()))
+			fValue = fValue.Elem()
+		}
+
+		mustBeString(TAG_FIELD_SLUG_FROM, sf.field, fValue)
+		fValue.SetString(makeSlug(sourceValue.String()))
+
+		failures := len(o.errors)
+		if valErr := validateFieldValue(sf.params, sf.field, fValue, structValue, o); valErr != nil {
+			return present, valErr
+		}
+
+		if len(o.errors) == failures {
+			present = append(present, presentField{name: sf.name, params: sf.params, field: sf.field, value: fValue})
+		}
+	}
+
+	return present, nil
+}
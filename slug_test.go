@@ -0,0 +1,43 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	type article struct {
+		Title string `validate:"name=title,required"`
+		Slug  string `validate:"name=slug,format=slug,maxLen=24,slugfrom=title"`
+	}
+
+	var out article
+	checkError(t, Validate(decodeInput(t, `{"title":"Crème Brûlée: A  Recipe!"}`), &out), nil)
+	if out.Slug != "creme-brulee-a-recipe" {
+		t.Fatalf("unexpected generated slug '%s'", out.Slug)
+	}
+
+	out = article{}
+	checkError(t, Validate(decodeInput(t, `{"title":"Hello","slug":"custom-slug"}`), &out), nil)
+	if out.Slug != "custom-slug" {
+		t.Fatalf("expected the given slug to be kept, got '%s'", out.Slug)
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"title":"Hello","slug":"Not A Slug"}`, &ValidateError{ParamName: "slug", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"title":"Hello","slug":"trailing-"}`, &ValidateError{ParamName: "slug", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"title":"A very long title for a slug"}`, &ValidateError{ParamName: "slug", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"title":"!!!"}`, &ValidateError{ParamName: "slug", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out article
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValidateStruct applies the validate tags to the current values of an
+// already populated struct. A field holding its zero value is treated as
+// absent: it fails if the field is required, gets its default if one is
+// declared and is skipped by the remaining rules otherwise.
+func ValidateStruct(outputStruct interface{}) error {
+	outValue := checkOutputStruct(outputStruct)
+
+	if valErr := validatePopulated(outValue.Elem(), "", 0, newOptions(nil)); valErr != nil {
+		return valErr
+	}
+
+	return nil
+}
+
+func validatePopulated(structValue reflect.Value, prefix string, depth int, o *options) *ValidateError {
+	structType := structValue.Type()
+	present := make([]presentField, 0, structType.NumField())
+	var slugs, absent []presentField
+
+	for _, tf := range taggedFields(structType) {
+		structField := structType.Field(tf.index)
+		fValue := structValue.Field(tf.index)
+		vParams := o.enabledRules(o.overridden(structType, structField, tf.params))
+		name := vParams.Name
+		vParams.Name = prefix + name
+
+		if fValue.IsZero() {
+			if _, ok := vParams.Fields[TAG_FIELD_SLUG_FROM]; ok {
+				slugs = append(slugs, presentField{name: name, params: vParams, field: structField, value: fValue})
+				continue
+			}
+
+			if _, ok := vParams.Fields[TAG_FIELD_REQUIRED_IF]; ok {
+				absent = append(absent, presentField{name: name, params: vParams, field: structField, value: fValue})
+			}
+
+			if vParams.Required {
+				return &ValidateError{
+					ParamName:     vParams.Name,
+					Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+					OriginalError: fmt.Errorf("Param '%s' is required", vParams.Name),
+				}
+			}
+
+			if v, ok := vParams.Fields[TAG_FIELD_DEFAULT]; ok {
+				setDefaultValue(fValue.Addr(), v)
+			} else {
+				continue
+			}
+		}
+
+		if isNestedSlice(fValue.Type()) {
+			if valErr := validatePopulatedSlice(fValue, vParams.Name, depth, o); valErr != nil {
+				return valErr
+			}
+		} else if isNestedStruct(fValue.Type()) {
+			if valErr := validatePopulatedStruct(fValue, vParams.Name, depth, o); valErr != nil {
+				return valErr
+			}
+		} else if _, ok := vParams.Fields[TAG_FIELD_DISCRIMINATOR]; ok {
+			if valErr := validatePopulatedDiscriminated(vParams, structField, fValue, depth, o); valErr != nil {
+				return valErr
+			}
+		}
+
+		if fValue.Kind() == reflect.Ptr {
+			fValue = fValue.Elem()
+		}
+
+		applyTransforms(vParams, structField, fValue)
+
+		if valErr := validateFieldValue(vParams, structField, fValue, structValue, o); valErr != nil {
+			return valErr
+		}
+
+		present = append(present, presentField{name: name, params: vParams, field: structField, value: fValue})
+	}
+
+	present, valErr := fillSlugs(slugs, present, structValue, o)
+	if valErr != nil {
+		return valErr
+	}
+
+	if valErr := checkRequiredIf(absent, present, o); valErr != nil {
+		return valErr
+	}
+
+	if valErr := checkCrossFields(present, o); valErr != nil {
+		return valErr
+	}
+
+	if valErr := checkStructRules(structValue, present, o); valErr != nil {
+		return valErr
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestValidateStruct(t *testing.T) {
+	type account struct {
+		Login string `validate:"name=login,required,minLen=3,maxLen=8"`
+		Role  string `validate:"name=role,oneof=admin user,default=user"`
+		Age   int    `validate:"name=age,min=18"`
+	}
+
+	tests := []struct {
+		name  string
+		input account
+		want  *ValidateError
+	}{
+		{"valid", account{Login: "alice", Role: "admin", Age: 30}, nil},
+		{"zero age skipped", account{Login: "alice"}, nil},
+		{"missing login", account{Role: "admin"}, &ValidateError{ParamName: "login", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{"short login", account{Login: "al"}, &ValidateError{ParamName: "login", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{"unknown role", account{Login: "alice", Role: "root"}, &ValidateError{ParamName: "role", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{"too young", account{Login: "alice", Age: 12}, &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.input
+			checkError(t, ValidateStruct(&in), tt.want)
+		})
+	}
+}
+
+func TestValidateStructDefault(t *testing.T) {
+	type account struct {
+		Role string `validate:"name=role,default=user"`
+	}
+
+	var in account
+	checkError(t, ValidateStruct(&in), nil)
+	if in.Role != "user" {
+		t.Fatalf("expected the default role, got '%s'", in.Role)
+	}
+}
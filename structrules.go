@@ -0,0 +1,293 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+type structRule func(structValue reflect.Value, present []presentField) *ValidateError
+
+// StructRule is a rule involving several fields of a struct. It is checked
+// after all the fields are decoded, see RegisterStructRule.
+type StructRule struct {
+	check structRule
+}
+
+var structRules = struct {
+	sync.RWMutex
+	m map[reflect.Type][]structRule
+}{
+	m: make(map[reflect.Type][]structRule),
+}
+
+// RegisterExpr adds a text/template condition evaluated against every
+// validated value of structType, e.g. `{{ gt .Max .Min }}`. The validation
+// fails unless the template renders "true".
+func RegisterExpr(structType interface{}, expr string) {
+	tmpl, err := template.New("expr").Option("missingkey=error").Parse(expr)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse expression '%s': %s", expr, err.Error()))
+	}
+
+	registerStructRule(structType, func(structValue reflect.Value, present []presentField) *ValidateError {
+		var out strings.Builder
+		if err := tmpl.Execute(&out, structValue.Interface()); err != nil {
+			return &ValidateError{
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Expression '%s' failed: %s", expr, err.Error()),
+			}
+		}
+
+		if strings.TrimSpace(out.String()) != "true" {
+			return &ValidateError{
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Expression '%s' is not satisfied", expr),
+			}
+		}
+
+		return nil
+	})
+}
+
+// RegisterStructRule attaches rules to structType, they are checked every
+// time a value of that type is validated.
+func RegisterStructRule(structType interface{}, rules ...StructRule) {
+	for _, rule := range rules {
+		registerStructRule(structType, rule.check)
+	}
+}
+
+func registerStructRule(structType interface{}, rule structRule) {
+	t := structTypeOf(structType)
+	if t == nil {
+		panic("Struct rules can only be registered for a struct type")
+	}
+
+	structRules.Lock()
+	defer structRules.Unlock()
+
+	structRules.m[t] = append(structRules.m[t], rule)
+}
+
+// structTypeOf accepts a reflect.Type or a value and returns the struct type
+// behind it, or nil if there is none.
+func structTypeOf(structType interface{}) reflect.Type {
+	t, ok := structType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(structType)
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return t
+}
+
+func checkStructRules(structValue reflect.Value, present []presentField, o *options) *ValidateError {
+	structRules.RLock()
+	rules := structRules.m[structValue.Type()]
+	structRules.RUnlock()
+
+	for _, rule := range rules {
+		if valErr := o.fail(rule(structValue, present)); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+func quoteParams(group []string) string {
+	return "'" + strings.Join(group, "', '") + "'"
+}
+
+// SumEquals requires the numeric params of the group to add up to target
+// within tolerance. Absent params count as zero.
+func SumEquals(group []string, target float64, tolerance float64) StructRule {
+	return StructRule{check: func(structValue reflect.Value, present []presentField) *ValidateError {
+		sum := 0.0
+		for _, name := range group {
+			fValue, ok := findPresent(present, name)
+			if !ok {
+				continue
+			}
+
+			v, ok := numericAsFloat(fValue)
+			if !ok {
+				panic(fmt.Sprintf("SumEquals: param '%s' of '%s' is not an integer or float", name, structValue.Type().Name()))
+			}
+
+			sum += v
+		}
+
+		if math.Abs(sum-target) > tolerance {
+			return &ValidateError{
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Params %s sum to %v instead of %v", quoteParams(group), sum, target),
+			}
+		}
+
+		return nil
+	}}
+}
+
+// ExactlyN requires exactly n params of the group to be present.
+func ExactlyN(group []string, n int) StructRule {
+	return StructRule{check: func(structValue reflect.Value, present []presentField) *ValidateError {
+		count := 0
+		for _, name := range group {
+			if _, ok := findPresent(present, name); ok {
+				count++
+			}
+		}
+
+		if count != n {
+			return &ValidateError{
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Exactly %d of params %s must be present, got %d", n, quoteParams(group), count),
+			}
+		}
+
+		return nil
+	}}
+}
+
+// ReferentialIntegrity requires the childKey param of every element of the
+// childSlice param to equal the parentKey param of an element of the
+// parentSlice param. Zero child keys, e.g. of root records, are not checked.
+func ReferentialIntegrity(childSlice, childKey, parentSlice, parentKey string) StructRule {
+	return StructRule{check: func(structValue reflect.Value, present []presentField) *ValidateError {
+		children, ok := findPresent(present, childSlice)
+		if !ok {
+			return nil
+		}
+
+		keys := make(map[interface{}]bool)
+		if parents, ok := findPresent(present, parentSlice); ok {
+			for i := 0; i < parents.Len(); i++ {
+				if key, ok := elemParam(parents.Index(i), parentKey); ok {
+					keys[key.Interface()] = true
+				}
+			}
+		}
+
+		for i := 0; i < children.Len(); i++ {
+			key, ok := elemParam(children.Index(i), childKey)
+			if !ok || key.IsZero() || keys[key.Interface()] {
+				continue
+			}
+
+			name := fmt.Sprintf("%s[%d].%s", childSlice, i, childKey)
+			return &ValidateError{
+				ParamName:     name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' refers to a missing '%s' %v", name, parentSlice, key.Interface()),
+			}
+		}
+
+		return nil
+	}}
+}
+
+// elemParam returns the field tagged with the param name in a struct
+// element, which may be a pointer.
+func elemParam(elem reflect.Value, param string) (reflect.Value, bool) {
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return reflect.Value{}, false
+		}
+
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Param '%s' is looked up in a %s, not in a struct", param, elem.Type()))
+	}
+
+	for _, tf := range taggedFields(elem.Type()) {
+		if tf.params.Name == param {
+			return elem.Field(tf.index), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// ValidDate requires the integer params to form an existing calendar date,
+// Feb 30 is rejected for instance. The rule is skipped unless all three
+// params are present.
+func ValidDate(yearField, monthField, dayField string) StructRule {
+	return StructRule{check: func(structValue reflect.Value, present []presentField) *ValidateError {
+		var parts [3]int
+		for i, name := range []string{yearField, monthField, dayField} {
+			fValue, ok := findPresent(present, name)
+			if !ok {
+				return nil
+			}
+
+			v, ok := numericAsFloat(fValue)
+			if !ok || v != math.Trunc(v) {
+				panic(fmt.Sprintf("ValidDate: param '%s' of '%s' is not an integer", name, structValue.Type().Name()))
+			}
+
+			parts[i] = int(v)
+		}
+
+		date := time.Date(parts[0], time.Month(parts[1]), parts[2], 0, 0, 0, 0, time.UTC)
+		if date.Year() != parts[0] || int(date.Month()) != parts[1] || date.Day() != parts[2] {
+			return &ValidateError{
+				Code: VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Params %s do not form a valid date",
+					quoteParams([]string{yearField, monthField, dayField})),
+			}
+		}
+
+		return nil
+	}}
+}
+
+// AspectRatio requires width/height to be within tolerance of ratio, e.g.
+// 16.0/9 for widescreen media. The rule is skipped unless both params are
+// present.
+func AspectRatio(widthField, heightField string, ratio float64, tolerance float64) StructRule {
+	return StructRule{check: func(structValue reflect.Value, present []presentField) *ValidateError {
+		var dims [2]float64
+		for i, name := range []string{widthField, heightField} {
+			fValue, ok := findPresent(present, name)
+			if !ok {
+				return nil
+			}
+
+			v, ok := numericAsFloat(fValue)
+			if !ok {
+				panic(fmt.Sprintf("AspectRatio: param '%s' of '%s' is not an integer or float", name, structValue.Type().Name()))
+			}
+
+			dims[i] = v
+		}
+
+		if dims[1] == 0 || math.Abs(dims[0]/dims[1]-ratio) > tolerance {
+			return &ValidateError{
+				Code: VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Params %s do not have the aspect ratio %v",
+					quoteParams([]string{widthField, heightField}), ratio),
+			}
+		}
+
+		return nil
+	}}
+}
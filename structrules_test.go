@@ -0,0 +1,180 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+type testPriceRange struct {
+	Min int `validate:"name=min"`
+	Max int `validate:"name=max"`
+}
+
+func init() {
+	RegisterExpr(testPriceRange{}, `{{ gt .Max .Min }}`)
+}
+
+func TestRegisterExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"min":1,"max":5}`, nil},
+		{`{"min":5,"max":5}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"min":7,"max":5}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testPriceRange
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+type testSplit struct {
+	Cash  int `validate:"name=cash"`
+	Card  int `validate:"name=card"`
+	Bonus int `validate:"name=bonus"`
+}
+
+func init() {
+	RegisterStructRule(testSplit{}, SumEquals([]string{"cash", "card", "bonus"}, 100, 0))
+}
+
+func TestSumEquals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"cash":50,"card":40,"bonus":10}`, nil},
+		{`{"cash":60,"card":40}`, nil},
+		{`{"cash":50,"card":40,"bonus":9}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testSplit
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+type testBirthday struct {
+	Year  int `validate:"name=year"`
+	Month int `validate:"name=month"`
+	Day   int `validate:"name=day"`
+}
+
+func init() {
+	RegisterStructRule(testBirthday{}, ValidDate("year", "month", "day"))
+}
+
+func TestValidDate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"year":2024,"month":2,"day":29}`, nil},
+		{`{"year":2024,"month":2}`, nil},
+		{`{"year":2024,"month":2,"day":30}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"year":2023,"month":2,"day":29}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"year":2024,"month":13,"day":1}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testBirthday
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+type testContact struct {
+	Email string `validate:"name=email"`
+	Phone string `validate:"name=phone"`
+	Fax   string `validate:"name=fax"`
+	Post  string `validate:"name=post"`
+}
+
+func init() {
+	RegisterStructRule(testContact{}, ExactlyN([]string{"email", "phone", "fax", "post"}, 2))
+}
+
+func TestExactlyN(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"email":"a@example.com","phone":"+1"}`, nil},
+		{`{"email":"a@example.com","phone":"+1","fax":"+2"}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"post":"Main St 1"}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testContact
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+type testCategory struct {
+	ID       int `validate:"name=id"`
+	ParentID int `validate:"name=parentId"`
+}
+
+type testCatalog struct {
+	Categories []testCategory `validate:"name=categories"`
+}
+
+func init() {
+	RegisterStructRule(testCatalog{}, ReferentialIntegrity("categories", "parentId", "categories", "id"))
+}
+
+func TestReferentialIntegrity(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"categories":[{"id":1},{"id":2,"parentId":1},{"id":3,"parentId":2}]}`, nil},
+		{`{"categories":[{"id":1},{"id":2,"parentId":7}]}`, &ValidateError{ParamName: "categories[1].parentId", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testCatalog
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+type testVideo struct {
+	Width  int `validate:"name=width"`
+	Height int `validate:"name=height"`
+}
+
+func init() {
+	RegisterStructRule(testVideo{}, AspectRatio("width", "height", 16.0/9, 0.01))
+}
+
+func TestAspectRatio(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"width":1920,"height":1080}`, nil},
+		{`{"width":1280,"height":720}`, nil},
+		{`{"width":1920}`, nil},
+		{`{"width":1024,"height":768}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"width":1920,"height":0}`, &ValidateError{Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testVideo
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type taggedField struct {
+	index  int
+	params FieldValidationParams
+}
+
+// tagCache maps a struct type to its decoded validate tags, they are parsed
+// once per type. The cached params are shared and must not be modified.
+var tagCache sync.Map
+
+func taggedFields(structType reflect.Type) []taggedField {
+	if fields, ok := tagCache.Load(structType); ok {
+		return fields.([]taggedField)
+	}
+
+	fields := make([]taggedField, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		tagValue, ok := structField.Tag.Lookup(VALIDATE_TAG_NAME)
+		if !ok {
+			continue
+		}
+
+		tagFieldsRaw := splitTagFields(tagValue)
+		if len(tagFieldsRaw) == 0 {
+			panic(fmt.Sprintf("Field '%s': empty tag", structField.Name))
+		}
+
+		fields = append(fields, taggedField{index: i, params: decodeTagFields(tagFieldsRaw)})
+	}
+
+	tagCache.Store(structType, fields)
+	return fields
+}
+
+// paramName returns the param name of a field as declared in its tag, the
+// name of vParams is prefixed with the path of the nested structs.
+func paramName(structType reflect.Type, structField reflect.StructField) string {
+	for _, tf := range taggedFields(structType) {
+		if tf.index == structField.Index[0] {
+			return tf.params.Name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type benchPayload struct {
+	ID       int64   `validate:"name=id,required,min=1"`
+	Name     string  `validate:"name=name,required,minLen=1,maxLen=64"`
+	Email    string  `validate:"name=email,email"`
+	Role     string  `validate:"name=role,oneof=admin user guest"`
+	Age      int     `validate:"name=age,min=0,max=150"`
+	Score    float64 `validate:"name=score,gte=0,lte=100"`
+	Country  string  `validate:"name=country,len=2"`
+	Website  string  `validate:"name=website,url"`
+	Nickname string  `validate:"name=nickname,trim,maxLen=32"`
+	Active   bool    `validate:"name=active,default=true"`
+}
+
+var benchInput = `{"id":42,"name":"Ada","email":"ada@example.com","role":"admin","age":36,
+	"score":99.5,"country":"GB","website":"https://example.com","nickname":" ada "}`
+
+func TestTaggedFieldsCached(t *testing.T) {
+	structType := reflect.TypeOf(benchPayload{})
+	first := taggedFields(structType)
+	if len(first) != structType.NumField() {
+		t.Fatalf("got %d tagged fields, want %d", len(first), structType.NumField())
+	}
+
+	if &taggedFields(structType)[0] != &first[0] {
+		t.Fatal("the tags were decoded again")
+	}
+}
+
+func TestTaggedFieldsPanicsUntilFixed(t *testing.T) {
+	type broken struct {
+		A int `validate:"name=a,name=b"`
+	}
+
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic on the malformed tag")
+				}
+			}()
+
+			var out broken
+			Validate(map[string]*json.RawMessage{}, &out)
+		}()
+	}
+}
+
+func benchmarkValidate(b *testing.B, cached bool) {
+	var inputData map[string]*json.RawMessage
+	if err := json.Unmarshal([]byte(benchInput), &inputData); err != nil {
+		b.Fatal(err)
+	}
+
+	structType := reflect.TypeOf(benchPayload{})
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !cached {
+			tagCache.Delete(structType)
+		}
+
+		var out benchPayload
+		if err := Validate(inputData, &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateCachedTags(b *testing.B) {
+	benchmarkValidate(b, true)
+}
+
+func BenchmarkValidateUncachedTags(b *testing.B) {
+	benchmarkValidate(b, false)
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const TIME_PREDICATE_BUSINESS_DAY = "businessday"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// now is replaced in tests to get a stable clock.
+var now = time.Now
+
+var timePredicates = struct {
+	sync.RWMutex
+	m map[string]func(time.Time) bool
+}{
+	m: map[string]func(time.Time) bool{
+		TIME_PREDICATE_BUSINESS_DAY: isBusinessDay,
+	},
+}
+
+// RegisterTimePredicate makes fn available to time.Time fields through
+// the timepredicate tag. It panics if the name is already registered.
+func RegisterTimePredicate(name string, fn func(time.Time) bool) {
+	if name == "" || fn == nil {
+		panic("Time predicate must have a name and a function")
+	}
+
+	timePredicates.Lock()
+	defer timePredicates.Unlock()
+
+	if _, ok := timePredicates.m[name]; ok {
+		panic(fmt.Sprintf("Time predicate '%s' already registered", name))
+	}
+
+	timePredicates.m[name] = fn
+}
+
+func isBusinessDay(t time.Time) bool {
+	day := t.Weekday()
+	return day != time.Saturday && day != time.Sunday
+}
+
+func mustBeTime(tagName string, structField reflect.StructField, fValue reflect.Value) time.Time {
+	if fValue.Type() != timeType {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a time.Time", tagName, structField.Name))
+	}
+
+	return fValue.Interface().(time.Time)
+}
+
+func checkTimePredicate(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, name string) *ValidateError {
+	t := mustBeTime(TAG_FIELD_TIME_PREDICATE, structField, fValue)
+
+	timePredicates.RLock()
+	fn, ok := timePredicates.m[name]
+	timePredicates.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("Unknown time predicate '%s' on field '%s'", name, structField.Name))
+	}
+
+	if fn(t) {
+		return nil
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_INVALID,
+		OriginalError: fmt.Errorf("Param '%s' does not satisfy '%s'", vParams.Name, name),
+	}
+}
+
+func checkFreshness(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	t := mustBeTime(TAG_FIELD_FRESHNESS, structField, fValue)
+
+	tolerance, err := time.ParseDuration(tagRawVal)
+	if err != nil || tolerance < 0 {
+		panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as a duration", TAG_FIELD_FRESHNESS, structField.Name))
+	}
+
+	diff := now().Sub(t)
+	if diff < 0 {
+		diff = -diff
+	}
+
+	if diff > tolerance {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_INVALID,
+			OriginalError: fmt.Errorf("Param '%s' is more than %s away from the current time", vParams.Name, tolerance),
+		}
+	}
+
+	return nil
+}
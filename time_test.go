@@ -0,0 +1,57 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimePredicateBusinessDay(t *testing.T) {
+	type meeting struct {
+		At time.Time `validate:"name=at,timepredicate=businessday"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"at":"2024-01-09T10:00:00Z"}`, nil},
+		{`{"at":"2024-01-06T10:00:00Z"}`, &ValidateError{ParamName: "at", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out meeting
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	type ping struct {
+		SentAt time.Time `validate:"name=sentAt,freshness=30s"`
+	}
+
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2024, 1, 9, 10, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"sentAt":"2024-01-09T09:59:45Z"}`, nil},
+		{`{"sentAt":"2024-01-09T10:00:20Z"}`, nil},
+		{`{"sentAt":"2024-01-09T09:58:00Z"}`, &ValidateError{ParamName: "sentAt", Code: VALIDATE_ERR_CODE_INVALID}},
+		{`{"sentAt":"2024-01-09T10:01:00Z"}`, &ValidateError{ParamName: "sentAt", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out ping
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// normalizers maps the unicode normalization tags to their implementation.
+// They are provided by norm.go, which is only built with the "norm" build
+// tag so that golang.org/x/text stays an optional dependency.
+var normalizers = map[string]func(string) string{}
+
+var stringTransforms = []struct {
+	tagName   string
+	transform func(string) string
+}{
+	{TAG_FIELD_TRIM, strings.TrimSpace},
+	{TAG_FIELD_LOWER, strings.ToLower},
+	{TAG_FIELD_UPPER, strings.ToUpper},
+}
+
+// applyTransforms rewrites the decoded value of a field before the rules
+// are checked against it. The value is trimmed, then case-folded and then
+// normalized.
+func applyTransforms(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value) {
+	for _, t := range stringTransforms {
+		if _, ok := vParams.Fields[t.tagName]; !ok {
+			continue
+		}
+
+		mustBeString(t.tagName, structField, fValue)
+		fValue.SetString(t.transform(fValue.String()))
+	}
+
+	for _, tagName := range []string{TAG_FIELD_NFC, TAG_FIELD_NFKC} {
+		if _, ok := vParams.Fields[tagName]; !ok {
+			continue
+		}
+
+		mustBeString(tagName, structField, fValue)
+
+		normalize, ok := normalizers[tagName]
+		if !ok {
+			panic(fmt.Sprintf("Tag '%s' of field '%s' requires building with the 'norm' build tag",
+				tagName, structField.Name))
+		}
+
+		fValue.SetString(normalize(fValue.String()))
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestTransforms(t *testing.T) {
+	type signup struct {
+		Login   string  `validate:"name=login,trim,lower,minLen=3,oneof=neo trinity"`
+		Country string  `validate:"name=country,trim,upper,len=2"`
+		Note    *string `validate:"name=note,trim,maxLen=4"`
+	}
+
+	var out signup
+	checkError(t, Validate(decodeInput(t, `{"login":"  NEO ","country":" ua","note":" memo  "}`), &out), nil)
+	if out.Login != "neo" || out.Country != "UA" || out.Note == nil || *out.Note != "memo" {
+		t.Fatalf("unexpected transformed values: %+v", out)
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"login":"  ab  "}`, &ValidateError{ParamName: "login", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{`{"login":"Morpheus"}`, &ValidateError{ParamName: "login", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"country":" ukr "}`, &ValidateError{ParamName: "country", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out signup
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestNestedTransforms(t *testing.T) {
+	type tags struct {
+		Codes  []string `validate:"name=codes,dive,trim,maxLen=2"`
+		Labels []string `validate:"name=labels,headrule=trim;upper;len=2"`
+	}
+
+	var out tags
+	checkError(t, Validate(decodeInput(t, `{"codes":[" ab ","cd"],"labels":[" ua ","x "]}`), &out), nil)
+	if out.Codes[0] != "ab" || out.Labels[0] != "UA" || out.Labels[1] != "x " {
+		t.Fatalf("unexpected transformed values: %+v", out)
+	}
+
+	checkError(t, Validate(decodeInput(t, `{"codes":["ab"," abc "]}`), &out),
+		&ValidateError{ParamName: "codes[1]", Code: VALIDATE_ERR_CODE_TOO_LONG})
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var units = struct {
+	sync.RWMutex
+	m map[string]func(v float64) float64
+}{
+	m: make(map[string]func(v float64) float64),
+}
+
+// RegisterUnit makes a unit available to the converttomin and converttomax
+// tags, toCanonical converts a value in that unit into the canonical unit
+// the bounds are expressed in. It panics if the unit is already registered.
+func RegisterUnit(name string, toCanonical func(v float64) float64) {
+	if name == "" || toCanonical == nil {
+		panic("Unit must have a name and a conversion")
+	}
+
+	units.Lock()
+	defer units.Unlock()
+
+	if _, ok := units.m[name]; ok {
+		panic(fmt.Sprintf("Unit '%s' already registered", name))
+	}
+
+	units.m[name] = toCanonical
+}
+
+// checkConvertedBound bounds the value once converted from the unit held by
+// another param, tagged as converttomin=unitParam:bound. A value without a
+// unit param is taken as canonical.
+func checkConvertedBound(pf presentField, present []presentField, tagName, tagRawVal string) *ValidateError {
+	parts := strings.SplitN(tagRawVal, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as unitParam:bound", tagName, pf.field.Name))
+	}
+
+	bound, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to parse '%s' tag of field '%s' as unitParam:bound", tagName, pf.field.Name))
+	}
+
+	v, ok := numericAsFloat(pf.value)
+	if !ok {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not an integer or float", tagName, pf.field.Name))
+	}
+
+	if unit, ok := findPresent(present, parts[0]); ok {
+		if unit.Kind() != reflect.String {
+			panic(fmt.Sprintf("Tag '%s' of field '%s' refers to '%s' which is not a string", tagName, pf.field.Name, parts[0]))
+		}
+
+		units.RLock()
+		toCanonical, ok := units.m[unit.String()]
+		units.RUnlock()
+		if !ok {
+			return &ValidateError{
+				ParamName:     pf.params.Name,
+				Code:          VALIDATE_ERR_CODE_INVALID,
+				OriginalError: fmt.Errorf("Param '%s' has unknown unit '%s'", pf.params.Name, unit.String()),
+			}
+		}
+
+		v = toCanonical(v)
+	}
+
+	if tagName == TAG_FIELD_CONVERT_TO_MIN && v < bound {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_SMALL,
+			OriginalError: fmt.Errorf("Param '%s' is too small (< %v in the canonical unit)", pf.params.Name, bound),
+		}
+	}
+
+	if tagName == TAG_FIELD_CONVERT_TO_MAX && v > bound {
+		return &ValidateError{
+			ParamName:     pf.params.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_BIG,
+			OriginalError: fmt.Errorf("Param '%s' is too big (> %v in the canonical unit)", pf.params.Name, bound),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func init() {
+	RegisterUnit("testkm", func(v float64) float64 { return v * 1000 })
+	RegisterUnit("testmi", func(v float64) float64 { return v * 1609.344 })
+}
+
+func TestConvertedBounds(t *testing.T) {
+	type run struct {
+		Unit     string  `validate:"name=unit"`
+		Distance float64 `validate:"name=distance,converttomin=unit:100,converttomax=unit:42195"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"unit":"testkm","distance":42}`, nil},
+		{`{"unit":"testmi","distance":26}`, nil},
+		{`{"distance":500}`, nil},
+		{`{"unit":"testmi","distance":27}`, &ValidateError{ParamName: "distance", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"unit":"testkm","distance":0.05}`, &ValidateError{ParamName: "distance", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"distance":50000}`, &ValidateError{ParamName: "distance", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"unit":"parsec","distance":1}`, &ValidateError{ParamName: "distance", Code: VALIDATE_ERR_CODE_INVALID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out run
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
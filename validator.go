@@ -5,28 +5,145 @@
 package validate
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const VALIDATE_TAG_NAME = "validate"
 
+var jsonNull = json.RawMessage("null")
+
+// StrictTags makes the malformed bounds of the min, max, gt, gte, lt, lte,
+// minLen, maxLen, len, maxbytes, sumMin, sumMax, minvalues, maxvalues,
+// displaywidth and maxtext tags panic. When it is false they fail the
+// validation with VALIDATE_ERR_CODE_UNKNOWN instead, for tags generated at
+// runtime.
+var StrictTags = true
+
+// TAG_RULE_SEPARATOR separates the rules nested into a single tag field,
+// e.g. headrule=minLen=1;maxLen=8. The fields following a bare dive are
+// nested the same way, dive,min=1,max=9 is dive=min=1;max=9.
+const TAG_RULE_SEPARATOR = ";"
+
 const (
-	TAG_FIELD_NAME     = "name"
-	TAG_FIELD_REQUIRED = "required"
-	TAG_FIELD_MAX      = "max"
-	TAG_FIELD_MIN      = "min"
-	TAG_FIELD_MAX_LEN  = "maxLen"
-	TAG_FIELD_MIN_LEN  = "minLen"
-	TAG_FIELD_DEFAULT  = "default"
-	TAG_FIELD_ONE_OF   = "oneof"
+	TAG_FIELD_NAME           = "name"
+	TAG_FIELD_REQUIRED       = "required"
+	TAG_FIELD_MAX            = "max"
+	TAG_FIELD_MIN            = "min"
+	TAG_FIELD_MAX_LEN        = "maxLen"
+	TAG_FIELD_MIN_LEN        = "minLen"
+	TAG_FIELD_DEFAULT        = "default"
+	TAG_FIELD_ONE_OF         = "oneof"
+	TAG_FIELD_FORMAT         = "format"
+	TAG_FIELD_TIME_PREDICATE = "timepredicate"
+	TAG_FIELD_SUM_MAX        = "sumMax"
+	TAG_FIELD_SUM_MIN        = "sumMin"
+	TAG_FIELD_MAX_VALUES     = "maxvalues"
+	TAG_FIELD_MIN_VALUES     = "minvalues"
+	TAG_FIELD_CONTIGUOUS     = "contiguous"
+	TAG_FIELD_DECIMAL        = "decimal"
+	TAG_FIELD_PHONE_REGION   = "phoneregion"
+	TAG_FIELD_FRESHNESS      = "freshness"
+	TAG_FIELD_DISPLAY_WIDTH  = "displaywidth"
+	TAG_FIELD_HMAC           = "hmac"
+	TAG_FIELD_MONEY_SCALE    = "moneyscale"
+	TAG_FIELD_UNIQUE_BY      = "uniqueby"
+	TAG_FIELD_STRICT_NUMERIC = "strictnumeric"
+	TAG_FIELD_POW2           = "pow2"
+	TAG_FIELD_MOD_CHECK      = "modcheck"
+	// TAG_FIELD_VALUE_PATTERN and TAG_FIELD_PATTERN take the rest of the tag,
+	// commas included, so either has to be the last field of the tag.
+	TAG_FIELD_VALUE_PATTERN  = "valuepattern"
+	TAG_FIELD_VALUE_FORMAT   = "valueformat"
+	TAG_FIELD_EQ_EXPECTED    = "eqexpected"
+	TAG_FIELD_HEAD_RULE      = "headrule"
+	TAG_FIELD_NFC            = "nfc"
+	TAG_FIELD_NFKC           = "nfkc"
+	TAG_FIELD_ZSCORE         = "zscore"
+	TAG_FIELD_SOFT_MAX       = "softmax"
+	TAG_FIELD_DISJOINT       = "disjoint"
+	TAG_FIELD_EXISTS         = "exists"
+	TAG_FIELD_MAX_BYTES      = "maxbytes"
+	TAG_FIELD_SKIP_FLAG      = "skipflag"
+	TAG_FIELD_INCREASING     = "increasing"
+	TAG_FIELD_INDEX_INTO     = "indexinto"
+	TAG_FIELD_REQUIRES       = "requires"
+	TAG_FIELD_DECRYPTABLE    = "decryptable"
+	TAG_FIELD_MIN_ENTROPY    = "minentropy"
+	TAG_FIELD_VALUE_SUM      = "valuesum"
+	TAG_FIELD_DISCRIMINATOR  = "discriminator"
+	TAG_FIELD_ROUNDED_TO     = "roundedto"
+	TAG_FIELD_DIVE           = "dive"
+	TAG_FIELD_MASK           = "mask"
+	TAG_FIELD_PATTERN        = "pattern"
+	TAG_FIELD_MONOTONIC      = "monotonic"
+	TAG_FIELD_SLUG_FROM      = "slugfrom"
+	TAG_FIELD_CONVERT_TO_MIN = "converttomin"
+	TAG_FIELD_CONVERT_TO_MAX = "converttomax"
+	TAG_FIELD_EXACT_INT      = "exactint"
+	TAG_FIELD_LEN            = "len"
+	TAG_FIELD_IN_SET         = "inset"
+	TAG_FIELD_ONE_OF_CI      = "oneofci"
+	TAG_FIELD_EMAIL_CANON    = "emailcanon"
+	TAG_FIELD_GT             = "gt"
+	TAG_FIELD_GTE            = "gte"
+	TAG_FIELD_LT             = "lt"
+	TAG_FIELD_LTE            = "lte"
+	TAG_FIELD_REQUIRED_IF    = "required_if"
+	TAG_FIELD_TRIM           = "trim"
+	TAG_FIELD_LOWER          = "lower"
+	TAG_FIELD_UPPER          = "upper"
+	TAG_FIELD_INTEGRAL       = "integral"
+	TAG_FIELD_NON_NIL_ELEMS  = "nonnilelems"
+	TAG_FIELD_CUSTOM         = "custom"
+	TAG_FIELD_EMAIL          = "email"
+	TAG_FIELD_MAX_TEXT       = "maxtext"
+	TAG_FIELD_URL            = "url"
+	TAG_FIELD_UUID           = "uuid"
+	TAG_FIELD_GT_FIELD       = "gtfield"
+	TAG_FIELD_LT_FIELD       = "ltfield"
+	TAG_FIELD_PERMUTATION_OF = "permutationof"
 )
 
+// tagFieldNames are the known tag fields.
+var tagFieldNames = map[string]bool{}
+
+func init() {
+	for _, name := range []string{
+		TAG_FIELD_NAME, TAG_FIELD_REQUIRED, TAG_FIELD_MAX, TAG_FIELD_MIN, TAG_FIELD_MAX_LEN,
+		TAG_FIELD_MIN_LEN, TAG_FIELD_DEFAULT, TAG_FIELD_ONE_OF, TAG_FIELD_FORMAT,
+		TAG_FIELD_TIME_PREDICATE, TAG_FIELD_SUM_MAX, TAG_FIELD_SUM_MIN, TAG_FIELD_MAX_VALUES,
+		TAG_FIELD_MIN_VALUES, TAG_FIELD_CONTIGUOUS, TAG_FIELD_DECIMAL, TAG_FIELD_PHONE_REGION,
+		TAG_FIELD_FRESHNESS, TAG_FIELD_DISPLAY_WIDTH, TAG_FIELD_HMAC, TAG_FIELD_MONEY_SCALE,
+		TAG_FIELD_UNIQUE_BY, TAG_FIELD_STRICT_NUMERIC, TAG_FIELD_POW2, TAG_FIELD_MOD_CHECK,
+		TAG_FIELD_VALUE_PATTERN, TAG_FIELD_VALUE_FORMAT, TAG_FIELD_EQ_EXPECTED, TAG_FIELD_HEAD_RULE,
+		TAG_FIELD_NFC, TAG_FIELD_NFKC, TAG_FIELD_ZSCORE, TAG_FIELD_SOFT_MAX, TAG_FIELD_DISJOINT,
+		TAG_FIELD_EXISTS, TAG_FIELD_MAX_BYTES, TAG_FIELD_SKIP_FLAG, TAG_FIELD_INCREASING,
+		TAG_FIELD_INDEX_INTO, TAG_FIELD_REQUIRES, TAG_FIELD_DECRYPTABLE, TAG_FIELD_MIN_ENTROPY,
+		TAG_FIELD_VALUE_SUM, TAG_FIELD_DISCRIMINATOR, TAG_FIELD_ROUNDED_TO, TAG_FIELD_DIVE,
+		TAG_FIELD_MASK, TAG_FIELD_PATTERN, TAG_FIELD_MONOTONIC, TAG_FIELD_SLUG_FROM,
+		TAG_FIELD_CONVERT_TO_MIN, TAG_FIELD_CONVERT_TO_MAX, TAG_FIELD_EXACT_INT, TAG_FIELD_LEN,
+		TAG_FIELD_IN_SET, TAG_FIELD_ONE_OF_CI, TAG_FIELD_EMAIL_CANON, TAG_FIELD_GT, TAG_FIELD_GTE,
+		TAG_FIELD_LT, TAG_FIELD_LTE, TAG_FIELD_REQUIRED_IF, TAG_FIELD_TRIM, TAG_FIELD_LOWER,
+		TAG_FIELD_UPPER, TAG_FIELD_INTEGRAL, TAG_FIELD_NON_NIL_ELEMS, TAG_FIELD_CUSTOM,
+		TAG_FIELD_EMAIL, TAG_FIELD_MAX_TEXT, TAG_FIELD_URL, TAG_FIELD_UUID, TAG_FIELD_GT_FIELD,
+		TAG_FIELD_LT_FIELD, TAG_FIELD_PERMUTATION_OF,
+	} {
+		tagFieldNames[name] = true
+	}
+}
+
+// ErrorCode classifies validation errors, its values are stable.
+type ErrorCode int
+
 const (
-	VALIDATE_ERR_CODE_UNKNOWN = iota
+	VALIDATE_ERR_CODE_UNKNOWN ErrorCode = iota
 	VALIDATE_ERR_CODE_MISSING_REQ_PARAM
 	VALIDATE_ERR_CODE_UNPARSABLE
 	VALIDATE_ERR_CODE_TOO_LONG
@@ -34,10 +151,33 @@ const (
 	VALIDATE_ERR_CODE_TOO_BIG
 	VALIDATE_ERR_CODE_TOO_SMALL
 	VALIDATE_ERR_CODE_INVALID
+	VALIDATE_ERR_CODE_NOT_ALLOWED
 )
 
+var errorCodeNames = [...]string{
+	VALIDATE_ERR_CODE_UNKNOWN:           "UNKNOWN",
+	VALIDATE_ERR_CODE_MISSING_REQ_PARAM: "MISSING_REQ_PARAM",
+	VALIDATE_ERR_CODE_UNPARSABLE:        "UNPARSABLE",
+	VALIDATE_ERR_CODE_TOO_LONG:          "TOO_LONG",
+	VALIDATE_ERR_CODE_TOO_SHORT:         "TOO_SHORT",
+	VALIDATE_ERR_CODE_TOO_BIG:           "TOO_BIG",
+	VALIDATE_ERR_CODE_TOO_SMALL:         "TOO_SMALL",
+	VALIDATE_ERR_CODE_INVALID:           "INVALID",
+	VALIDATE_ERR_CODE_NOT_ALLOWED:       "NOT_ALLOWED",
+}
+
+// String returns the name of the code without the VALIDATE_ERR_CODE_ prefix,
+// e.g. "TOO_LONG".
+func (c ErrorCode) String() string {
+	if c >= 0 && int(c) < len(errorCodeNames) {
+		return errorCodeNames[c]
+	}
+
+	return "ErrorCode(" + strconv.Itoa(int(c)) + ")"
+}
+
 type ValidateError struct {
-	Code          int
+	Code          ErrorCode
 	ParamName     string
 	OriginalError error
 }
@@ -60,44 +200,93 @@ type FieldValidationParams struct {
 	Fields   map[string]string
 }
 
-func Validate(inputData map[string]*json.RawMessage, outputStruct interface{}) error {
-	outValue := reflect.ValueOf(outputStruct)
-	if outValue.Kind() != reflect.Ptr {
-		panic("input argument is not a pointer")
-	}
+func Validate(inputData map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) error {
+	return ValidateContext(context.Background(), inputData, outputStruct, opts...)
+}
 
-	if outValue.IsNil() {
-		panic("input argument is a nil pointer")
-	}
+// ValidateContext is Validate bound to ctx. The context is used by the checks
+// doing I/O, e.g. its deadline limits format=reachable requests.
+func ValidateContext(ctx context.Context, inputData map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	o.ctx = ctx
+	outValue := checkOutputStruct(outputStruct)
 
-	outType := outValue.Elem().Type()
-	if outType.Kind() != reflect.Struct {
-		panic("input argument should be a poiner to a struct")
+	if valErr := validateInput(inputData, outValue.Elem(), "", 0, o); valErr != nil {
+		return valErr
 	}
 
-	for i := 0; i < outType.NumField(); i++ {
-		structField := outType.Field(i)
-		tagValue, ok := structField.Tag.Lookup(VALIDATE_TAG_NAME)
-		if !ok {
-			continue
-		}
+	return nil
+}
+
+// ValidateAll is Validate reporting every error instead of the first one. A
+// field failing several checks gets an error for each of them. The result is
+// empty if the input is valid.
+func ValidateAll(inputData map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) []*ValidateError {
+	o := newOptions(opts)
+	o.collectAll = true
+	outValue := checkOutputStruct(outputStruct)
 
-		tagFieldsRaw := strings.Split(tagValue, ",")
-		if len(tagFieldsRaw) == 0 {
-			panic(fmt.Sprintf("Field '%s': empty tag", structField.Name))
+	validateInput(inputData, outValue.Elem(), "", 0, o)
+
+	return o.errors
+}
+
+// ValidateMerged is Validate against several inputs, e.g. the query, the
+// headers and the body of a request. A param is taken from the first source
+// supplying it, the defaults apply only if none does.
+func ValidateMerged(sources []map[string]*json.RawMessage, outputStruct interface{}, opts ...Option) error {
+	merged := make(map[string]*json.RawMessage)
+	for _, source := range sources {
+		for name, val := range source {
+			if _, ok := merged[name]; !ok {
+				merged[name] = val
+			}
 		}
+	}
 
-		fValue := outValue.Elem().FieldByName(structField.Name)
-		vParams := decodeTagFields(tagFieldsRaw)
+	return Validate(merged, outputStruct, opts...)
+}
+
+// validateInput decodes and validates the params of a single struct. Nested
+// structs are validated with the prefix prepended to their param names.
+func validateInput(inputData map[string]*json.RawMessage, structValue reflect.Value, prefix string, depth int, o *options) *ValidateError {
+	structType := structValue.Type()
+	present := make([]presentField, 0, structType.NumField())
+	var slugs, absent []presentField
+
+	for _, tf := range taggedFields(structType) {
+		structField := structType.Field(tf.index)
+		fValue := structValue.Field(tf.index)
+		vParams := o.enabledRules(o.overridden(structType, structField, tf.params))
+		name := vParams.Name
+		vParams.Name = prefix + name
+
+		failures := len(o.errors)
+		val, ok := inputData[name]
+		if ok && val == nil {
+			val = &jsonNull
+		}
 
-		val, ok := inputData[vParams.Name]
 		if !ok {
+			if _, ok := vParams.Fields[TAG_FIELD_SLUG_FROM]; ok {
+				slugs = append(slugs, presentField{name: name, params: vParams, field: structField, value: fValue})
+				continue
+			}
+
+			if _, ok := vParams.Fields[TAG_FIELD_REQUIRED_IF]; ok {
+				absent = append(absent, presentField{name: name, params: vParams, field: structField, value: fValue})
+			}
+
 			if vParams.Required {
-				return &ValidateError{
+				if valErr := o.fail(&ValidateError{
 					ParamName:     vParams.Name,
 					Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
 					OriginalError: fmt.Errorf("Param '%s' is required", vParams.Name),
+				}); valErr != nil {
+					return valErr
 				}
+
+				continue
 			}
 
 			if v, ok := vParams.Fields[TAG_FIELD_DEFAULT]; ok {
@@ -106,130 +295,490 @@ func Validate(inputData map[string]*json.RawMessage, outputStruct interface{}) e
 				continue
 			}
 		} else {
-			errDecode := json.Unmarshal(*val, fValue.Addr().Interface())
-			if errDecode != nil {
-				return &ValidateError{
-					ParamName:     vParams.Name,
-					Code:          VALIDATE_ERR_CODE_UNPARSABLE,
-					OriginalError: errDecode,
+			if o.strictTypes {
+				if valErr := o.fail(checkJSONType(vParams.Name, *val, fValue.Type())); valErr != nil {
+					return valErr
 				}
 			}
-		}
 
-		for tagName, tagRawVal := range vParams.Fields {
-			switch tagName {
-			case TAG_FIELD_MIN, TAG_FIELD_MAX:
-				valErr := ValidateError{
-					ParamName:     vParams.Name,
-					Code:          VALIDATE_ERR_CODE_UNKNOWN,
-					OriginalError: nil,
+			if isNestedSlice(fValue.Type()) {
+				if valErr := decodeNestedSlice(*val, fValue, vParams.Name, depth, o); valErr != nil {
+					return valErr
+				}
+			} else if isNestedStruct(fValue.Type()) {
+				if valErr := decodeNestedStruct(*val, fValue, vParams.Name, depth, o); valErr != nil {
+					return valErr
 				}
-				var val interface{}
-				var err error
-				switch fValue.Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int32, reflect.Int64:
-					val, err = strconv.ParseInt(tagRawVal, 10, 64)
-					if err != nil {
-						panic(fmt.Sprintf("Unable to parse '%s' tag as a signed integer", tagName))
+			} else if _, ok := vParams.Fields[TAG_FIELD_DISCRIMINATOR]; ok {
+				if valErr := o.fail(decodeDiscriminated(*val, vParams, structField, fValue, depth, o)); valErr != nil {
+					return valErr
+				}
+			} else {
+				errDecode := json.Unmarshal(*val, fValue.Addr().Interface())
+				if errDecode != nil {
+					if valErr := o.fail(&ValidateError{
+						ParamName:     vParams.Name,
+						Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+						OriginalError: errDecode,
+					}); valErr != nil {
+						return valErr
 					}
 
-					if tagName == TAG_FIELD_MIN && fValue.Int() < val.(int64) {
-						valErr.Code = VALIDATE_ERR_CODE_TOO_SMALL
-					}
+					continue
+				}
 
-					if tagName == TAG_FIELD_MAX && fValue.Int() > val.(int64) {
-						valErr.Code = VALIDATE_ERR_CODE_TOO_BIG
-					}
-				case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
-					val, err = strconv.ParseUint(tagRawVal, 10, 64)
-					if err != nil {
-						panic(fmt.Sprintf("Unable to parse '%s' tag as a unsigned integer", tagName))
+				if _, ok := vParams.Fields[TAG_FIELD_EXACT_INT]; ok {
+					if valErr := o.fail(checkExactNumber(vParams, structField, fValue, *val)); valErr != nil {
+						return valErr
 					}
+				}
 
-					if tagName == TAG_FIELD_MIN && fValue.Uint() < val.(uint64) {
-						valErr.Code = VALIDATE_ERR_CODE_TOO_SMALL
-					}
+				if o.emptyStringAsNil && isEmptyStringPtr(fValue) {
+					fValue.Set(reflect.Zero(fValue.Type()))
+					continue
+				}
+			}
+		}
 
-					if tagName == TAG_FIELD_MAX && fValue.Uint() > val.(uint64) {
-						valErr.Code = VALIDATE_ERR_CODE_TOO_BIG
-					}
-				case reflect.Float32, reflect.Float64:
-					val, err = strconv.ParseFloat(tagRawVal, 64)
-					if err != nil {
-						panic(fmt.Sprintf("Unable to parse default (%s) as a float: %s", tagRawVal, err.Error()))
-					}
+		if len(o.errors) > failures {
+			continue
+		}
 
-					if tagName == TAG_FIELD_MIN && fValue.Float() < val.(float64) {
-						valErr.Code = VALIDATE_ERR_CODE_TOO_SMALL
-					}
+		// The checks apply to the pointee, a nil pointer has nothing to check
+		if fValue.Kind() == reflect.Ptr {
+			if fValue.IsNil() {
+				continue
+			}
 
-					if tagName == TAG_FIELD_MAX && fValue.Float() > val.(float64) {
-						valErr.Code = VALIDATE_ERR_CODE_TOO_BIG
-					}
-				default:
-					panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
-						"The field is not an integer or float", tagName, structField.Name))
-				}
+			fValue = fValue.Elem()
+		}
 
-				switch valErr.Code {
-				case VALIDATE_ERR_CODE_TOO_SMALL:
-					valErr.OriginalError = fmt.Errorf("Param '%s' is too small (< %v)", valErr.ParamName, val)
-				case VALIDATE_ERR_CODE_TOO_BIG:
-					valErr.OriginalError = fmt.Errorf("Param '%s' is too big (> %v)", valErr.ParamName, val)
-				}
-			case TAG_FIELD_MIN_LEN, TAG_FIELD_MAX_LEN:
-				if fValue.Kind() != reflect.String {
-					panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
-						"The field is not a string", tagName, structField.Name))
-				}
+		applyTransforms(vParams, structField, fValue)
 
-				reqLen, err := strconv.ParseUint(tagRawVal, 10, 64)
-				if err != nil {
-					panic(fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", tagName))
-				}
+		if valErr := validateFieldValue(vParams, structField, fValue, structValue, o); valErr != nil {
+			return valErr
+		}
 
-				if tagName == TAG_FIELD_MAX_LEN && len(fValue.String()) > int(reqLen) {
-					return &ValidateError{
-						ParamName:     vParams.Name,
-						Code:          VALIDATE_ERR_CODE_TOO_LONG,
-						OriginalError: fmt.Errorf("Param '%s' is too long (> %d)", vParams.Name, reqLen),
-					}
-				}
+		if len(o.errors) > failures {
+			continue
+		}
 
-				if tagName == TAG_FIELD_MIN_LEN && len(fValue.String()) < int(reqLen) {
-					return &ValidateError{
-						ParamName:     vParams.Name,
-						Code:          VALIDATE_ERR_CODE_TOO_SHORT,
-						OriginalError: fmt.Errorf("Param '%s' is too short (< %d)", vParams.Name, reqLen),
-					}
-				}
-			case TAG_FIELD_ONE_OF:
+		present = append(present, presentField{name: name, params: vParams, field: structField, value: fValue})
+	}
 
-			case TAG_FIELD_DEFAULT:
-				// This tag already processed
-			default:
-				panic(fmt.Sprintf("Unknown tag field: '%s'", tagName))
+	present, valErr := fillSlugs(slugs, present, structValue, o)
+	if valErr != nil {
+		return valErr
+	}
+
+	if valErr := checkRequiredIf(absent, present, o); valErr != nil {
+		return valErr
+	}
+
+	if valErr := checkCrossFields(present, o); valErr != nil {
+		return valErr
+	}
+
+	if valErr := checkStructRules(structValue, present, o); valErr != nil {
+		return valErr
+	}
+
+	return nil
+}
+
+func checkOutputStruct(outputStruct interface{}) reflect.Value {
+	outValue := reflect.ValueOf(outputStruct)
+	if outValue.Kind() != reflect.Ptr {
+		panic("input argument is not a pointer")
+	}
+
+	if outValue.IsNil() {
+		panic("input argument is a nil pointer")
+	}
+
+	outType := outValue.Elem().Type()
+	if outType.Kind() != reflect.Struct {
+		panic("input argument should be a poiner to a struct")
+	}
+
+	return outValue
+}
+
+func validateFieldValue(vParams FieldValidationParams, structField reflect.StructField, fValue, structValue reflect.Value, o *options) *ValidateError {
+	tagNames := make([]string, 0, len(vParams.Fields))
+	for tagName := range vParams.Fields {
+		tagNames = append(tagNames, tagName)
+	}
+	sort.Strings(tagNames)
+
+	for _, tagName := range tagNames {
+		if valErr := o.fail(validateTag(tagName, vParams.Fields[tagName], vParams, structField, fValue, structValue, o)); valErr != nil {
+			return valErr
+		}
+	}
+
+	return nil
+}
+
+func validateTag(tagName, tagRawVal string, vParams FieldValidationParams, structField reflect.StructField, fValue, structValue reflect.Value, o *options) *ValidateError {
+	switch tagName {
+	case TAG_FIELD_MIN, TAG_FIELD_MAX, TAG_FIELD_GT, TAG_FIELD_GTE, TAG_FIELD_LT, TAG_FIELD_LTE:
+		valErr := ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_UNKNOWN,
+			OriginalError: nil,
+		}
+		var val interface{}
+		var err error
+		// cmp is the sign of the field value minus the bound
+		var cmp int
+		switch fValue.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			val, err = strconv.ParseInt(tagRawVal, 10, 64)
+			if err != nil {
+				return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as a signed integer", tagName))
+			}
+
+			switch v, bound := fValue.Int(), val.(int64); {
+			case v < bound:
+				cmp = -1
+			case v > bound:
+				cmp = 1
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			val, err = strconv.ParseUint(tagRawVal, 10, 64)
+			if err != nil {
+				return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as a unsigned integer", tagName))
+			}
+
+			switch v, bound := fValue.Uint(), val.(uint64); {
+			case v < bound:
+				cmp = -1
+			case v > bound:
+				cmp = 1
+			}
+		case reflect.Float32, reflect.Float64:
+			val, err = strconv.ParseFloat(tagRawVal, 64)
+			if err != nil {
+				return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse default (%s) as a float: %s", tagRawVal, err.Error()))
+			}
+
+			switch v, bound := fValue.Float(), val.(float64); {
+			case v < bound:
+				cmp = -1
+			case v > bound:
+				cmp = 1
+			}
+		default:
+			panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+				"The field is not an integer or float", tagName, structField.Name))
+		}
+
+		var op string
+		switch {
+		case (tagName == TAG_FIELD_MIN || tagName == TAG_FIELD_GTE) && cmp < 0:
+			valErr.Code, op = VALIDATE_ERR_CODE_TOO_SMALL, "<"
+		case tagName == TAG_FIELD_GT && cmp <= 0:
+			valErr.Code, op = VALIDATE_ERR_CODE_TOO_SMALL, "<="
+		case (tagName == TAG_FIELD_MAX || tagName == TAG_FIELD_LTE) && cmp > 0:
+			valErr.Code, op = VALIDATE_ERR_CODE_TOO_BIG, ">"
+		case tagName == TAG_FIELD_LT && cmp >= 0:
+			valErr.Code, op = VALIDATE_ERR_CODE_TOO_BIG, ">="
+		}
+
+		switch valErr.Code {
+		case VALIDATE_ERR_CODE_TOO_SMALL:
+			valErr.OriginalError = fmt.Errorf("Param '%s' is too small (%s %v)", valErr.ParamName, op, val)
+			return &valErr
+		case VALIDATE_ERR_CODE_TOO_BIG:
+			valErr.OriginalError = fmt.Errorf("Param '%s' is too big (%s %v)", valErr.ParamName, op, val)
+			return &valErr
+		}
+	case TAG_FIELD_MIN_LEN, TAG_FIELD_MAX_LEN:
+		var strLen int
+		switch fValue.Kind() {
+		case reflect.String:
+			strLen = utf8.RuneCountInString(fValue.String())
+		case reflect.Slice, reflect.Array, reflect.Map:
+			strLen = fValue.Len()
+		default:
+			panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+				"The field is not a string, slice, array or map", tagName, structField.Name))
+		}
+
+		reqLen, err := strconv.ParseUint(tagRawVal, 10, 64)
+		if err != nil {
+			return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", tagName))
+		}
+
+		if tagName == TAG_FIELD_MAX_LEN && strLen > int(reqLen) {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_LONG,
+				OriginalError: fmt.Errorf("Param '%s' is too long (> %d)", vParams.Name, reqLen),
+			}
+		}
+
+		if tagName == TAG_FIELD_MIN_LEN && strLen < int(reqLen) {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_SHORT,
+				OriginalError: fmt.Errorf("Param '%s' is too short (< %d)", vParams.Name, reqLen),
+			}
+		}
+	case TAG_FIELD_IN_SET:
+		if valErr := checkInSet(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_EMAIL_CANON:
+		if valErr := checkEmailCanon(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_INTEGRAL:
+		if valErr := checkIntegral(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_NON_NIL_ELEMS:
+		if valErr := checkNonNilElems(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_CUSTOM:
+		if valErr := checkCustom(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_EMAIL:
+		if valErr := checkEmail(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_URL:
+		if valErr := checkURL(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_UUID:
+		if valErr := checkUUID(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_LEN:
+		mustBeString(tagName, structField, fValue)
+
+		reqLen, err := strconv.ParseUint(tagRawVal, 10, 64)
+		if err != nil {
+			return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", tagName))
+		}
+
+		strLen := utf8.RuneCountInString(fValue.String())
+		if strLen > int(reqLen) {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_LONG,
+				OriginalError: fmt.Errorf("Param '%s' is too long (!= %d)", vParams.Name, reqLen),
+			}
+		}
+
+		if strLen < int(reqLen) {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_SHORT,
+				OriginalError: fmt.Errorf("Param '%s' is too short (!= %d)", vParams.Name, reqLen),
 			}
 		}
+	case TAG_FIELD_MAX_BYTES:
+		mustBeString(tagName, structField, fValue)
+
+		maxBytes, err := strconv.ParseUint(tagRawVal, 10, 64)
+		if err != nil {
+			return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", tagName))
+		}
+
+		if len(fValue.String()) > int(maxBytes) {
+			return &ValidateError{
+				ParamName:     vParams.Name,
+				Code:          VALIDATE_ERR_CODE_TOO_LONG,
+				OriginalError: fmt.Errorf("Param '%s' is too long (> %d bytes)", vParams.Name, maxBytes),
+			}
+		}
+	case TAG_FIELD_ONE_OF, TAG_FIELD_ONE_OF_CI:
+		if valErr := checkOneOf(tagName, vParams, structField, fValue, structValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_FORMAT:
+		if valErr := checkFormat(vParams, structField, fValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_TIME_PREDICATE:
+		if valErr := checkTimePredicate(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_SUM_MIN, TAG_FIELD_SUM_MAX:
+		if valErr := checkSum(vParams, structField, fValue, tagName, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_MIN_VALUES, TAG_FIELD_MAX_VALUES:
+		if valErr := checkMapValues(vParams, structField, fValue, tagName, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_CONTIGUOUS:
+		if valErr := checkContiguous(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_DECIMAL:
+		if valErr := checkDecimal(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_FRESHNESS:
+		if valErr := checkFreshness(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_DISPLAY_WIDTH:
+		if valErr := checkDisplayWidth(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_MAX_TEXT:
+		if valErr := checkMaxText(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_UNIQUE_BY:
+		if valErr := checkUniqueBy(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_STRICT_NUMERIC:
+		if valErr := checkStrictNumeric(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_POW2:
+		if valErr := checkPow2(vParams, structField, fValue); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_MOD_CHECK:
+		if valErr := checkModN(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_VALUE_PATTERN:
+		if valErr := checkValuePattern(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_VALUE_FORMAT:
+		if valErr := checkValueFormat(vParams, structField, fValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_EQ_EXPECTED:
+		if valErr := checkEqExpected(vParams, structField, fValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_INCREASING:
+		if valErr := checkIncreasing(vParams, structField, fValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_HEAD_RULE:
+		if valErr := checkHeadRule(vParams, structField, fValue, structValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_DIVE:
+		if valErr := checkDive(vParams, structField, fValue, structValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_ZSCORE:
+		if valErr := checkZScore(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_EXISTS:
+		if valErr := checkExists(vParams, structField, fValue, tagRawVal, o); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_DECRYPTABLE:
+		if valErr := checkDecryptable(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_MIN_ENTROPY:
+		if valErr := checkMinEntropy(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_VALUE_SUM:
+		if valErr := checkValueSum(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_ROUNDED_TO:
+		if valErr := checkRoundedTo(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_PATTERN:
+		if valErr := checkPattern(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_MONOTONIC:
+		if valErr := checkMonotonic(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_MASK:
+		if valErr := checkMask(vParams, structField, fValue, tagRawVal); valErr != nil {
+			return valErr
+		}
+	case TAG_FIELD_SOFT_MAX:
+		checkSoftMax(vParams, structField, fValue, tagRawVal, o)
+	case TAG_FIELD_NFC, TAG_FIELD_NFKC, TAG_FIELD_TRIM, TAG_FIELD_LOWER, TAG_FIELD_UPPER:
+		// Applied before the checks
+	case TAG_FIELD_EXACT_INT:
+		// Checked against the raw input while decoding
+	case TAG_FIELD_PHONE_REGION, TAG_FIELD_HMAC, TAG_FIELD_MONEY_SCALE, TAG_FIELD_DISJOINT,
+		TAG_FIELD_INDEX_INTO, TAG_FIELD_REQUIRES, TAG_FIELD_CONVERT_TO_MIN, TAG_FIELD_CONVERT_TO_MAX,
+		TAG_FIELD_REQUIRED_IF, TAG_FIELD_GT_FIELD, TAG_FIELD_LT_FIELD,
+		TAG_FIELD_PERMUTATION_OF:
+		// Checked once all the fields are decoded
+	case TAG_FIELD_DEFAULT, TAG_FIELD_SKIP_FLAG, TAG_FIELD_DISCRIMINATOR, TAG_FIELD_SLUG_FROM:
+		// This tag already processed
+	default:
+		panic(fmt.Sprintf("Unknown tag field: '%s'", tagName))
 	}
 
 	return nil
 }
 
+// malformedTag panics with msg unless StrictTags is off, then the field fails
+// the validation instead.
+func malformedTag(vParams FieldValidationParams, structField reflect.StructField, msg string) *ValidateError {
+	if StrictTags {
+		panic(msg)
+	}
+
+	return &ValidateError{
+		ParamName:     vParams.Name,
+		Code:          VALIDATE_ERR_CODE_UNKNOWN,
+		OriginalError: fmt.Errorf("Field '%s': %s", structField.Name, msg),
+	}
+}
+
+func mustBeString(tagName string, structField reflect.StructField, fValue reflect.Value) {
+	if fValue.Kind() != reflect.String {
+		panic(fmt.Sprintf("Tag '%s' cannot be applied to field '%s'. "+
+			"The field is not a string", tagName, structField.Name))
+	}
+}
+
 func setDefaultValue(fieldPtr reflect.Value, rawValue string) {
 
 	field := reflect.Indirect(fieldPtr)
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	if strings.HasPrefix(rawValue, DEFAULT_FUNC_PREFIX+DEFAULT_FUNC_PREFIX) {
+		rawValue = strings.TrimPrefix(rawValue, DEFAULT_FUNC_PREFIX)
+	} else if setDefaultFromFunc(field, rawValue) {
+		return
+	}
+
 	kind := field.Kind()
 	switch kind {
-	case reflect.Uint:
-		val, err := strconv.ParseUint(rawValue, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, err := strconv.ParseUint(rawValue, 10, field.Type().Bits())
 		if err != nil {
 			panic(fmt.Sprintf("Unable to parse default (%s) as an unsigned integer: %s", rawValue, err.Error()))
 		}
 
 		field.SetUint(val)
-	case reflect.Int:
-		val, err := strconv.ParseInt(rawValue, 10, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := strconv.ParseInt(rawValue, 10, field.Type().Bits())
 		if err != nil {
 			panic(fmt.Sprintf("Unable to parse default (%s) as a signed integer: %s", rawValue, err.Error()))
 		}
@@ -249,6 +798,13 @@ func setDefaultValue(fieldPtr reflect.Value, rawValue string) {
 		}
 
 		field.SetFloat(val)
+	case reflect.Bool:
+		val, err := strconv.ParseBool(rawValue)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to parse default (%s) as a bool: %s", rawValue, err.Error()))
+		}
+
+		field.SetBool(val)
 	case reflect.String:
 		field.SetString(rawValue)
 	default:
@@ -256,6 +812,29 @@ func setDefaultValue(fieldPtr reflect.Value, rawValue string) {
 	}
 }
 
+// splitTagFields splits a validate tag into its fields. A pattern or
+// valuepattern field takes the rest of the tag, so that a regexp such as
+// ^[a-z]{2,3}$ keeps its commas. It has to be the last field of the tag,
+// a known tag field following it panics.
+// The rules nested with TAG_RULE_SEPARATOR are split on ';' only, a pattern
+// nested there cannot contain ';'.
+func splitTagFields(tagValue string) []string {
+	tagFieldsRaw := strings.Split(tagValue, ",")
+	for i, v := range tagFieldsRaw {
+		if strings.HasPrefix(v, TAG_FIELD_PATTERN+"=") || strings.HasPrefix(v, TAG_FIELD_VALUE_PATTERN+"=") {
+			for _, rest := range tagFieldsRaw[i+1:] {
+				if tagFieldNames[strings.SplitN(rest, "=", 2)[0]] {
+					panic(fmt.Sprintf("Tag field '%s' follows '%s', which takes the rest of the tag", rest, v))
+				}
+			}
+
+			return append(tagFieldsRaw[:i:i], strings.Join(tagFieldsRaw[i:], ","))
+		}
+	}
+
+	return tagFieldsRaw
+}
+
 func decodeTagFields(tagFieldsRaw []string) FieldValidationParams {
 	vParams := FieldValidationParams{
 		Name:     "",
@@ -263,6 +842,15 @@ func decodeTagFields(tagFieldsRaw []string) FieldValidationParams {
 		Fields:   make(map[string]string),
 	}
 
+	// A bare dive hands the fields following it over to the elements
+	for i, v := range tagFieldsRaw {
+		if v == TAG_FIELD_DIVE && i+1 < len(tagFieldsRaw) {
+			elemRules := strings.Join(tagFieldsRaw[i+1:], TAG_RULE_SEPARATOR)
+			tagFieldsRaw = append(tagFieldsRaw[:i:i], TAG_FIELD_DIVE+"="+elemRules)
+			break
+		}
+	}
+
 	seen := make(map[string]bool)
 
 	for _, v := range tagFieldsRaw {
@@ -0,0 +1,426 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeInput(t *testing.T, data string) map[string]*json.RawMessage {
+	t.Helper()
+
+	var inputData map[string]*json.RawMessage
+	if err := json.Unmarshal([]byte(data), &inputData); err != nil {
+		t.Fatalf("invalid test input %s: %s", data, err)
+	}
+
+	return inputData
+}
+
+// checkError fails the test unless err is a *ValidateError matching the
+// param name and code of want, or both are nil.
+func checkError(t *testing.T, err error, want *ValidateError) {
+	t.Helper()
+
+	if want == nil {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return
+	}
+
+	valErr, ok := err.(*ValidateError)
+	if !ok || valErr == nil {
+		t.Fatalf("expected an error on '%s', got %v", want.ParamName, err)
+	}
+
+	if valErr.ParamName != want.ParamName || valErr.Code != want.Code {
+		t.Fatalf("expected code %v on '%s', got code %v on '%s': %s",
+			want.Code, want.ParamName, valErr.Code, valErr.ParamName, valErr)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	type bounds struct {
+		I int     `validate:"name=i,min=-5,max=5"`
+		U uint    `validate:"name=u,min=2,max=5"`
+		F float64 `validate:"name=f,min=0.5,max=1.5"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"i":-5,"u":2,"f":0.5}`, nil},
+		{`{"i":5,"u":5,"f":1.5}`, nil},
+		{`{"i":-6}`, &ValidateError{ParamName: "i", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"i":6}`, &ValidateError{ParamName: "i", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"u":1}`, &ValidateError{ParamName: "u", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"u":6}`, &ValidateError{ParamName: "u", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"f":0.4}`, &ValidateError{ParamName: "f", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"f":1.6}`, &ValidateError{ParamName: "f", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out bounds
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	type column struct {
+		Title string `validate:"name=title,maxLen=4,maxbytes=6"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"title":"abcd"}`, nil},
+		{`{"title":"дім"}`, nil},
+		{`{"title":"тест"}`, &ValidateError{ParamName: "title", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"title":"abcde"}`, &ValidateError{ParamName: "title", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out column
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestIntegerWidths(t *testing.T) {
+	type widths struct {
+		I   int    `validate:"name=i,min=-2,max=2,default=1"`
+		I8  int8   `validate:"name=i8,min=-2,max=2,default=1"`
+		I16 int16  `validate:"name=i16,min=-2,max=2,default=1"`
+		I32 int32  `validate:"name=i32,min=-2,max=2,default=1"`
+		I64 int64  `validate:"name=i64,min=-2,max=2,default=1"`
+		U   uint   `validate:"name=u,min=1,max=2,default=1"`
+		U8  uint8  `validate:"name=u8,min=1,max=2,default=1"`
+		U16 uint16 `validate:"name=u16,min=1,max=2,default=1"`
+		U32 uint32 `validate:"name=u32,min=1,max=2,default=1"`
+		U64 uint64 `validate:"name=u64,min=1,max=2,default=1"`
+	}
+
+	var out widths
+	checkError(t, Validate(decodeInput(t, `{}`), &out), nil)
+	if out != (widths{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}) {
+		t.Fatalf("defaults were not set on every width: %+v", out)
+	}
+
+	for _, name := range []string{"i", "i8", "i16", "i32", "i64"} {
+		out = widths{}
+		checkError(t, Validate(decodeInput(t, `{"`+name+`":-3}`), &out), &ValidateError{ParamName: name, Code: VALIDATE_ERR_CODE_TOO_SMALL})
+		checkError(t, Validate(decodeInput(t, `{"`+name+`":3}`), &out), &ValidateError{ParamName: name, Code: VALIDATE_ERR_CODE_TOO_BIG})
+		checkError(t, Validate(decodeInput(t, `{"`+name+`":-2}`), &out), nil)
+	}
+
+	for _, name := range []string{"u", "u8", "u16", "u32", "u64"} {
+		out = widths{}
+		checkError(t, Validate(decodeInput(t, `{"`+name+`":0}`), &out), &ValidateError{ParamName: name, Code: VALIDATE_ERR_CODE_TOO_SMALL})
+		checkError(t, Validate(decodeInput(t, `{"`+name+`":3}`), &out), &ValidateError{ParamName: name, Code: VALIDATE_ERR_CODE_TOO_BIG})
+		checkError(t, Validate(decodeInput(t, `{"`+name+`":2}`), &out), nil)
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	type signup struct {
+		Login string `validate:"name=login,required"`
+		Code  string `validate:"name=code,minLen=4,oneof=abcd efgh"`
+		Age   int    `validate:"name=age,min=18"`
+	}
+
+	var out signup
+	if errs := ValidateAll(decodeInput(t, `{"login":"neo","code":"abcd","age":20}`), &out); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	errs := ValidateAll(decodeInput(t, `{"code":"xy","age":5}`), &out)
+	want := []*ValidateError{
+		{ParamName: "login", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM},
+		{ParamName: "code", Code: VALIDATE_ERR_CODE_TOO_SHORT},
+		{ParamName: "code", Code: VALIDATE_ERR_CODE_NOT_ALLOWED},
+		{ParamName: "age", Code: VALIDATE_ERR_CODE_TOO_SMALL},
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+
+	for i := range want {
+		checkError(t, errs[i], want[i])
+	}
+}
+
+func TestBoolDefaults(t *testing.T) {
+	type settings struct {
+		Notify  bool `validate:"name=notify,default=true"`
+		Archive bool `validate:"name=archive,default=false"`
+		Agreed  bool `validate:"name=agreed,required"`
+	}
+
+	var out settings
+	checkError(t, Validate(decodeInput(t, `{"agreed":false}`), &out), nil)
+	if !out.Notify || out.Archive || out.Agreed {
+		t.Fatalf("unexpected values: %+v", out)
+	}
+
+	out = settings{}
+	checkError(t, Validate(decodeInput(t, `{"notify":false,"archive":true,"agreed":true}`), &out), nil)
+	if out.Notify || !out.Archive || !out.Agreed {
+		t.Fatalf("unexpected values: %+v", out)
+	}
+
+	checkError(t, Validate(decodeInput(t, `{}`), &out), &ValidateError{ParamName: "agreed", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM})
+	checkError(t, Validate(decodeInput(t, `{"agreed":"yes"}`), &out), &ValidateError{ParamName: "agreed", Code: VALIDATE_ERR_CODE_UNPARSABLE})
+}
+
+func TestBoolDefaultInvalid(t *testing.T) {
+	type settings struct {
+		Notify bool `validate:"name=notify,default=maybe"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected default=maybe to panic")
+		}
+	}()
+
+	var out settings
+	Validate(decodeInput(t, `{}`), &out)
+}
+
+func TestPointerFields(t *testing.T) {
+	type patch struct {
+		Count *int     `validate:"name=count,min=0,max=10"`
+		Name  *string  `validate:"name=name,minLen=2,oneof=ab abc"`
+		Ratio *float64 `validate:"name=ratio,default=0.5"`
+	}
+
+	var out patch
+	checkError(t, Validate(decodeInput(t, `{}`), &out), nil)
+	if out.Count != nil || out.Name != nil {
+		t.Fatalf("expected absent params to stay nil: %+v", out)
+	}
+	if out.Ratio == nil || *out.Ratio != 0.5 {
+		t.Fatalf("expected the default ratio, got %v", out.Ratio)
+	}
+
+	out = patch{}
+	checkError(t, Validate(decodeInput(t, `{"count":0,"name":"abc"}`), &out), nil)
+	if out.Count == nil || *out.Count != 0 || out.Name == nil || *out.Name != "abc" {
+		t.Fatalf("expected the sent values: %+v", out)
+	}
+
+	checkError(t, Validate(decodeInput(t, `{"count":11}`), &out), &ValidateError{ParamName: "count", Code: VALIDATE_ERR_CODE_TOO_BIG})
+	checkError(t, Validate(decodeInput(t, `{"name":"a"}`), &out), &ValidateError{ParamName: "name", Code: VALIDATE_ERR_CODE_TOO_SHORT})
+	checkError(t, Validate(decodeInput(t, `{"name":"abcd"}`), &out), &ValidateError{ParamName: "name", Code: VALIDATE_ERR_CODE_NOT_ALLOWED})
+}
+
+func TestErrorCodeString(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		want string
+	}{
+		{VALIDATE_ERR_CODE_UNKNOWN, "UNKNOWN"},
+		{VALIDATE_ERR_CODE_MISSING_REQ_PARAM, "MISSING_REQ_PARAM"},
+		{VALIDATE_ERR_CODE_UNPARSABLE, "UNPARSABLE"},
+		{VALIDATE_ERR_CODE_TOO_LONG, "TOO_LONG"},
+		{VALIDATE_ERR_CODE_TOO_SHORT, "TOO_SHORT"},
+		{VALIDATE_ERR_CODE_TOO_BIG, "TOO_BIG"},
+		{VALIDATE_ERR_CODE_TOO_SMALL, "TOO_SMALL"},
+		{VALIDATE_ERR_CODE_INVALID, "INVALID"},
+		{VALIDATE_ERR_CODE_NOT_ALLOWED, "NOT_ALLOWED"},
+		{ErrorCode(42), "ErrorCode(42)"},
+		{ErrorCode(-1), "ErrorCode(-1)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.code.String(); got != tt.want {
+			t.Errorf("ErrorCode(%d).String() = %q, want %q", int(tt.code), got, tt.want)
+		}
+	}
+
+	if VALIDATE_ERR_CODE_UNKNOWN != 0 || VALIDATE_ERR_CODE_NOT_ALLOWED != 8 {
+		t.Fatal("the integer values of the error codes changed")
+	}
+}
+
+func TestExactLen(t *testing.T) {
+	type address struct {
+		Country string `validate:"name=country,len=2"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"country":"UA"}`, nil},
+		{`{"country":"ÜA"}`, nil},
+		{`{"country":"UKR"}`, &ValidateError{ParamName: "country", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"country":"U"}`, &ValidateError{ParamName: "country", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out address
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestExactLenNotString(t *testing.T) {
+	type address struct {
+		Zip int `validate:"name=zip,len=5"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected len on an int field to panic")
+		}
+	}()
+
+	var out address
+	Validate(decodeInput(t, `{"zip":12345}`), &out)
+}
+
+func TestCollectionLen(t *testing.T) {
+	type post struct {
+		Title string            `validate:"name=title,minLen=2,maxLen=4"`
+		Tags  []string          `validate:"name=tags,minLen=1,maxLen=2"`
+		Pair  [2]int            `validate:"name=pair,maxLen=2"`
+		Meta  map[string]string `validate:"name=meta,minLen=1,maxLen=2"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"title":"whée","tags":["a","b"],"pair":[1,2],"meta":{"k":"v"}}`, nil},
+		{`{"title":"w"}`, &ValidateError{ParamName: "title", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{`{"tags":[]}`, &ValidateError{ParamName: "tags", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{`{"tags":["a","b","c"]}`, &ValidateError{ParamName: "tags", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"meta":{}}`, &ValidateError{ParamName: "meta", Code: VALIDATE_ERR_CODE_TOO_SHORT}},
+		{`{"meta":{"a":"1","b":"2","c":"3"}}`, &ValidateError{ParamName: "meta", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out post
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestExclusiveBounds(t *testing.T) {
+	type bounds struct {
+		I int     `validate:"name=i,gt=0,lt=10"`
+		U uint    `validate:"name=u,gte=1,lte=9"`
+		F float64 `validate:"name=f,gt=0.5,lte=1.5"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"i":1,"u":1,"f":0.6}`, nil},
+		{`{"i":9,"u":9,"f":1.5}`, nil},
+		{`{"i":0}`, &ValidateError{ParamName: "i", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"i":10}`, &ValidateError{ParamName: "i", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"u":0}`, &ValidateError{ParamName: "u", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"u":10}`, &ValidateError{ParamName: "u", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+		{`{"f":0.5}`, &ValidateError{ParamName: "f", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`{"f":1.51}`, &ValidateError{ParamName: "f", Code: VALIDATE_ERR_CODE_TOO_BIG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out bounds
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestValidateMerged(t *testing.T) {
+	type request struct {
+		Token string `validate:"name=token,required"`
+		Page  int    `validate:"name=page,default=1"`
+	}
+
+	query := decodeInput(t, `{"page":3}`)
+	headers := decodeInput(t, `{"token":"abc","page":5}`)
+
+	var out request
+	checkError(t, ValidateMerged([]map[string]*json.RawMessage{query, headers}, &out), nil)
+	if out.Token != "abc" || out.Page != 3 {
+		t.Fatalf("unexpected values: %+v", out)
+	}
+
+	out = request{}
+	checkError(t, ValidateMerged([]map[string]*json.RawMessage{decodeInput(t, `{}`), decodeInput(t, `{"token":"abc"}`)}, &out), nil)
+	if out.Page != 1 {
+		t.Fatalf("expected the default page, got %d", out.Page)
+	}
+
+	checkError(t, ValidateMerged([]map[string]*json.RawMessage{query}, &out),
+		&ValidateError{ParamName: "token", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM})
+}
+
+func TestStrictTags(t *testing.T) {
+	type generated struct {
+		Age  int    `validate:"name=age,min=ten"`
+		Name string `validate:"name=name,maxLen=-1"`
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a malformed min to panic under StrictTags")
+			}
+		}()
+
+		var out generated
+		Validate(decodeInput(t, `{"age":5}`), &out)
+	}()
+
+	StrictTags = false
+	defer func() { StrictTags = true }()
+
+	var out generated
+	checkError(t, Validate(decodeInput(t, `{"age":5}`), &out), &ValidateError{ParamName: "age", Code: VALIDATE_ERR_CODE_UNKNOWN})
+	checkError(t, Validate(decodeInput(t, `{"name":"neo"}`), &out), &ValidateError{ParamName: "name", Code: VALIDATE_ERR_CODE_UNKNOWN})
+	checkError(t, Validate(decodeInput(t, `{}`), &out), nil)
+
+	type bounds struct {
+		Bytes  string         `validate:"name=bytes,maxbytes=x"`
+		Prices []float64      `validate:"name=prices,sumMax=x"`
+		Limits map[string]int `validate:"name=limits,minvalues=x"`
+		Label  string         `validate:"name=label,displaywidth=x"`
+		Body   string         `validate:"name=body,maxtext=x"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"bytes":"a"}`, &ValidateError{ParamName: "bytes", Code: VALIDATE_ERR_CODE_UNKNOWN}},
+		{`{"prices":[1]}`, &ValidateError{ParamName: "prices", Code: VALIDATE_ERR_CODE_UNKNOWN}},
+		{`{"limits":{"a":1}}`, &ValidateError{ParamName: "limits", Code: VALIDATE_ERR_CODE_UNKNOWN}},
+		{`{"label":"a"}`, &ValidateError{ParamName: "label", Code: VALIDATE_ERR_CODE_UNKNOWN}},
+		{`{"body":"a"}`, &ValidateError{ParamName: "body", Code: VALIDATE_ERR_CODE_UNKNOWN}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out bounds
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
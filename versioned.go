@@ -0,0 +1,110 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type versionKey struct {
+	structType reflect.Type
+	version    string
+}
+
+var versions = struct {
+	sync.RWMutex
+	m map[versionKey]Rules
+}{
+	m: make(map[versionKey]Rules),
+}
+
+// RegisterVersion sets the rules ValidateVersioned applies to the struct
+// type of outputStruct when the input carries the given schema version. The
+// Field of every rule must name a field of the struct. It panics if the
+// version is already registered for the type.
+func RegisterVersion(outputStruct interface{}, version string, rules Rules) {
+	t := structTypeOf(outputStruct)
+	if t == nil || version == "" {
+		panic("Version must have a struct type and a name")
+	}
+
+	rulesType, err := rules.structType()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	for i := 0; i < rulesType.NumField(); i++ {
+		rf := rulesType.Field(i)
+		f, ok := t.FieldByName(rf.Name)
+		if !ok || !rf.Type.AssignableTo(f.Type) {
+			panic(fmt.Sprintf("Rules of version '%s' do not match field '%s' of '%s'", version, rf.Name, t.Name()))
+		}
+	}
+
+	versions.Lock()
+	defer versions.Unlock()
+
+	key := versionKey{structType: t, version: version}
+	if _, ok := versions.m[key]; ok {
+		panic(fmt.Sprintf("Version '%s' of '%s' already registered", version, t.Name()))
+	}
+
+	versions.m[key] = rules
+}
+
+// ValidateVersioned reads the schema version from the versionField param of
+// inputData and validates the input with the rules registered for it. The
+// validated values are then stored in the fields of outputStruct.
+func ValidateVersioned(inputData map[string]*json.RawMessage, outputStruct interface{}, versionField string, opts ...Option) error {
+	outValue := checkOutputStruct(outputStruct)
+
+	rawVersion, ok := inputData[versionField]
+	if !ok || rawVersion == nil {
+		return &ValidateError{
+			ParamName:     versionField,
+			Code:          VALIDATE_ERR_CODE_MISSING_REQ_PARAM,
+			OriginalError: fmt.Errorf("Param '%s' is required", versionField),
+		}
+	}
+
+	var version interface{}
+	if err := json.Unmarshal(*rawVersion, &version); err != nil {
+		return &ValidateError{
+			ParamName:     versionField,
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	versions.RLock()
+	rules, ok := versions.m[versionKey{structType: outValue.Elem().Type(), version: fmt.Sprint(version)}]
+	versions.RUnlock()
+	if !ok {
+		return &ValidateError{
+			ParamName:     versionField,
+			Code:          VALIDATE_ERR_CODE_NOT_ALLOWED,
+			OriginalError: fmt.Errorf("Param '%s' is not a known version (%v)", versionField, version),
+		}
+	}
+
+	rulesType, err := rules.structType()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	rulesValue := reflect.New(rulesType)
+	if err := Validate(inputData, rulesValue.Interface(), opts...); err != nil {
+		return err
+	}
+
+	for i := 0; i < rulesType.NumField(); i++ {
+		outValue.Elem().FieldByName(rulesType.Field(i).Name).Set(rulesValue.Elem().Field(i))
+	}
+
+	return nil
+}
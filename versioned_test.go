@@ -0,0 +1,49 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+type testCustomer struct {
+	Name  string
+	Email string
+}
+
+func init() {
+	RegisterVersion(testCustomer{}, "1", Rules{Fields: []FieldRules{
+		{Field: "Name", Type: "string", Name: "name", Required: true},
+		{Field: "Email", Type: "string", Name: "email"},
+	}})
+	RegisterVersion(testCustomer{}, "2", Rules{Fields: []FieldRules{
+		{Field: "Name", Type: "string", Name: "name", Required: true},
+		{Field: "Email", Type: "string", Name: "email", Required: true},
+	}})
+}
+
+func TestValidateVersioned(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"version":1,"name":"neo"}`, nil},
+		{`{"version":"2","name":"neo","email":"neo@example.com"}`, nil},
+		{`{"version":2,"name":"neo"}`, &ValidateError{ParamName: "email", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`{"version":3,"name":"neo"}`, &ValidateError{ParamName: "version", Code: VALIDATE_ERR_CODE_NOT_ALLOWED}},
+		{`{"name":"neo"}`, &ValidateError{ParamName: "version", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out testCustomer
+			checkError(t, ValidateVersioned(decodeInput(t, tt.input), &out, "version"), tt.want)
+		})
+	}
+
+	var out testCustomer
+	checkError(t, ValidateVersioned(decodeInput(t, `{"version":2,"name":"neo","email":"neo@example.com"}`), &out, "version"), nil)
+	if out.Name != "neo" || out.Email != "neo@example.com" {
+		t.Fatalf("expected the validated values to be stored: %+v", out)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"regexp"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges lists the common East Asian wide and fullwidth ranges, each
+// rune in them takes two columns.
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe4f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1},
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1},
+	},
+}
+
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r) || unicode.IsControl(r):
+		case unicode.Is(wideRanges, r):
+			width += 2
+		default:
+			width++
+		}
+	}
+
+	return width
+}
+
+func checkDisplayWidth(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	mustBeString(TAG_FIELD_DISPLAY_WIDTH, structField, fValue)
+
+	maxWidth, err := strconv.ParseUint(tagRawVal, 10, 64)
+	if err != nil {
+		return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", TAG_FIELD_DISPLAY_WIDTH))
+	}
+
+	if displayWidth(fValue.String()) > int(maxWidth) {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_LONG,
+			OriginalError: fmt.Errorf("Param '%s' is too wide (> %d)", vParams.Name, maxWidth),
+		}
+	}
+
+	return nil
+}
+
+var htmlTagRegexp = regexp.MustCompile(`<!--[\s\S]*?-->|</?[A-Za-z][^>]*>`)
+
+// plainText drops the HTML comments and tags and unescapes the entities. It
+// does not parse HTML, a lone '<' is kept as text.
+func plainText(s string) string {
+	return html.UnescapeString(htmlTagRegexp.ReplaceAllString(s, ""))
+}
+
+func checkMaxText(vParams FieldValidationParams, structField reflect.StructField, fValue reflect.Value, tagRawVal string) *ValidateError {
+	mustBeString(TAG_FIELD_MAX_TEXT, structField, fValue)
+
+	maxLen, err := strconv.ParseUint(tagRawVal, 10, 64)
+	if err != nil {
+		return malformedTag(vParams, structField, fmt.Sprintf("Unable to parse '%s' tag as an unsigned integer", TAG_FIELD_MAX_TEXT))
+	}
+
+	if utf8.RuneCountInString(plainText(fValue.String())) > int(maxLen) {
+		return &ValidateError{
+			ParamName:     vParams.Name,
+			Code:          VALIDATE_ERR_CODE_TOO_LONG,
+			OriginalError: fmt.Errorf("Param '%s' is too long once stripped of HTML (> %d)", vParams.Name, maxLen),
+		}
+	}
+
+	return nil
+}
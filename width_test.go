@@ -0,0 +1,54 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestDisplayWidth(t *testing.T) {
+	type label struct {
+		Text string `validate:"name=text,displaywidth=6"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"text":"abcdef"}`, nil},
+		{`{"text":"日本語"}`, nil},
+		{`{"text":"cafés"}`, nil},
+		{`{"text":"abcdefg"}`, &ValidateError{ParamName: "text", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"text":"日本語です"}`, &ValidateError{ParamName: "text", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out label
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
+
+func TestMaxText(t *testing.T) {
+	type post struct {
+		Body string `validate:"name=body,maxtext=10"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`{"body":"<p><strong><em class=\"lead\">Hello</em></strong> <a href=\"https://example.com/a/long/path\">you</a></p><!-- draft -->"}`, nil},
+		{`{"body":"fish &amp; chips"}`, &ValidateError{ParamName: "body", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+		{`{"body":"<b>fish</b> &amp; chi"}`, nil},
+		{`{"body":"<p>a plain sentence</p>"}`, &ValidateError{ParamName: "body", Code: VALIDATE_ERR_CODE_TOO_LONG}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out post
+			checkError(t, Validate(decodeInput(t, tt.input), &out), tt.want)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "encoding/xml"
+
+// ValidateXML decodes data into outputStruct and then validates the decoded
+// values the same way ValidateStruct does.
+func ValidateXML(data []byte, outputStruct interface{}) error {
+	checkOutputStruct(outputStruct)
+
+	if err := xml.Unmarshal(data, outputStruct); err != nil {
+		return &ValidateError{
+			Code:          VALIDATE_ERR_CODE_UNPARSABLE,
+			OriginalError: err,
+		}
+	}
+
+	return ValidateStruct(outputStruct)
+}
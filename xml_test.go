@@ -0,0 +1,32 @@
+// Copyright 2018 Roman Strashkin.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package validate
+
+import "testing"
+
+func TestValidateXML(t *testing.T) {
+	type order struct {
+		Item     string `xml:"item" validate:"name=item,required"`
+		Quantity int    `xml:"quantity" validate:"name=quantity,min=1"`
+	}
+
+	tests := []struct {
+		input string
+		want  *ValidateError
+	}{
+		{`<order><item>book</item><quantity>2</quantity></order>`, nil},
+		{`<order><item>book</item><quantity>0</quantity></order>`, nil},
+		{`<order><item>book</item><quantity>-1</quantity></order>`, &ValidateError{ParamName: "quantity", Code: VALIDATE_ERR_CODE_TOO_SMALL}},
+		{`<order><quantity>2</quantity></order>`, &ValidateError{ParamName: "item", Code: VALIDATE_ERR_CODE_MISSING_REQ_PARAM}},
+		{`<order><item>book</item>`, &ValidateError{Code: VALIDATE_ERR_CODE_UNPARSABLE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var out order
+			checkError(t, ValidateXML([]byte(tt.input), &out), tt.want)
+		})
+	}
+}